@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	stdlog "log"
+
+	"github.com/openshift-knative/deviate/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+type configCmd struct {
+	*cli.Options
+	output string
+}
+
+func (c *configCmd) command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
+	show := &cobra.Command{
+		Use:       "show [project-dir]",
+		Short:     "Print the effective configuration, with the origin of each value",
+		ValidArgs: []string{"REPOSITORY"},
+		Args:      cobra.MaximumNArgs(1),
+		RunE:      c.show,
+	}
+	show.Flags().StringVarP(&c.output, "output", "o", string(cli.OutputYAML),
+		"output format, one of: yaml, json")
+	cmd.AddCommand(show, &cobra.Command{
+		Use:       "validate [project-dir]",
+		Short:     "Report all the problems of the configuration, without touching any branch",
+		ValidArgs: []string{"REPOSITORY"},
+		Args:      cobra.MaximumNArgs(1),
+		RunE:      c.validate,
+	}, &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the configuration file",
+		Args:  cobra.NoArgs,
+		RunE:  c.schema,
+	})
+	return cmd
+}
+
+func (c *configCmd) validate(cmd *cobra.Command, args []string) error {
+	logger := stdlog.New(cmd.ErrOrStderr(), "", 0)
+	return cli.ValidateConfig(logger, cmd.OutOrStdout(), //nolint:wrapcheck
+		sync{Options: c.Options}.project(args))
+}
+
+func (c *configCmd) schema(cmd *cobra.Command, _ []string) error {
+	return cli.ConfigSchema(cmd.OutOrStdout()) //nolint:wrapcheck
+}
+
+func (c *configCmd) show(cmd *cobra.Command, args []string) error {
+	logger := stdlog.New(cmd.ErrOrStderr(), "", 0)
+	return cli.ShowConfig(logger, cmd.OutOrStdout(), //nolint:wrapcheck
+		cli.OutputFormat(c.output), sync{Options: c.Options}.project(args))
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/openshift-knative/deviate/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+type gc struct {
+	*cli.Options
+}
+
+func (g gc) command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:       "gc [project-dir]",
+		Short:     "Close stale sync PRs, and delete stale sync branches",
+		ValidArgs: []string{"REPOSITORY"},
+		Args:      cobra.MaximumNArgs(1),
+		RunE:      g.run,
+	}
+	return cmd
+}
+
+func (g gc) run(cmd *cobra.Command, args []string) error {
+	return cli.GarbageCollect(cmd, sync{Options: g.Options}.project(args)) //nolint:wrapcheck
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/openshift-knative/deviate/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+type generate struct{}
+
+func (g generate) command() *cobra.Command {
+	return &cobra.Command{
+		Use:    cli.GenerateCommand + " params-file",
+		Short:  "Generate the images, with the dockerfilegen params of the file",
+		Hidden: true,
+		Args:   cobra.ExactArgs(1),
+		RunE:   g.run,
+	}
+}
+
+func (g generate) run(_ *cobra.Command, args []string) error {
+	return cli.GenerateImages(args[0]) //nolint:wrapcheck
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	stdlog "log"
+
+	"github.com/openshift-knative/deviate/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+type plan struct {
+	*cli.Options
+	output string
+}
+
+func (p *plan) command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:       "plan [project-dir]",
+		Short:     "Print the actions the synchronization would perform",
+		ValidArgs: []string{"REPOSITORY"},
+		Args:      cobra.MaximumNArgs(1),
+		RunE:      p.run,
+	}
+	cmd.Flags().StringVarP(&p.output, "output", "o", string(cli.OutputText),
+		"output format, one of: text, json")
+	return cmd
+}
+
+func (p *plan) run(cmd *cobra.Command, args []string) error {
+	logger := stdlog.New(cmd.ErrOrStderr(), "", 0)
+	return cli.Plan(logger, cmd.OutOrStdout(), //nolint:wrapcheck
+		cli.OutputFormat(p.output), sync{Options: p.Options}.project(args))
+}
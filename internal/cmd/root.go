@@ -28,7 +28,11 @@ func (a App) Command() *cobra.Command {
 	}
 	opts := &cli.Options{}
 	subs := []subcommand{
-		sync{opts},
+		&sync{Options: opts},
+		&plan{Options: opts},
+		gc{opts},
+		&configCmd{Options: opts},
+		generate{},
 	}
 	addFlags(cmd, opts)
 	for _, sub := range subs {
@@ -10,7 +10,12 @@ import (
 func TestRoot(t *testing.T) {
 	c := new(cmd.App).Command()
 
-	assert.Equal(t, len(c.Commands()), 1)
+	assert.Equal(t, len(c.Commands()), 5)
 	assert.Equal(t, c.Name(), "deviate")
-	assert.Equal(t, c.Commands()[0].Name(), "sync")
+	assert.Equal(t, c.Commands()[0].Name(), "config")
+	assert.Equal(t, c.Commands()[1].Name(), "gc")
+	assert.Equal(t, c.Commands()[2].Name(), "generate-images")
+	assert.Assert(t, c.Commands()[2].Hidden)
+	assert.Equal(t, c.Commands()[3].Name(), "plan")
+	assert.Equal(t, c.Commands()[4].Name(), "sync")
 }
@@ -2,7 +2,7 @@ package cmd
 
 import (
 	"os"
-	"path"
+	"path/filepath"
 
 	"github.com/openshift-knative/deviate/pkg/cli"
 	"github.com/openshift-knative/deviate/pkg/config"
@@ -11,9 +11,10 @@ import (
 
 type sync struct {
 	*cli.Options
+	resume bool
 }
 
-func (s sync) command() *cobra.Command {
+func (s *sync) command() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:       "sync [project-dir]",
 		Short:     "Synchronize to the upstream releases",
@@ -21,11 +22,14 @@ func (s sync) command() *cobra.Command {
 		Args:      cobra.MaximumNArgs(1),
 		RunE:      s.run,
 	}
+	cmd.Flags().BoolVar(&s.resume, "resume", false,
+		"resume the interrupted synchronization, skipping the completed steps")
 	return cmd
 }
 
-func (s sync) run(cmd *cobra.Command, args []string) error {
-	return cli.Sync(cmd, s.project(args)) //nolint:wrapcheck
+func (s *sync) run(cmd *cobra.Command, args []string) error {
+	return cli.Sync(cmd, s.project(args), //nolint:wrapcheck
+		cli.SyncOptions{Resume: s.resume})
 }
 
 func (s sync) project(args []string) func() config.Project {
@@ -38,8 +42,16 @@ func (s sync) project(args []string) func() config.Project {
 		if len(args) > 0 {
 			wd = args[0]
 		}
-		if !path.IsAbs(configPath) {
-			configPath = path.Join(wd, configPath)
+		if !filepath.IsAbs(configPath) {
+			configPath = filepath.Join(wd, configPath)
+		}
+		// The paths are made absolute up front, so they stay valid for the
+		// releases synchronized concurrently.
+		if abs, err := filepath.Abs(wd); err == nil {
+			wd = abs
+		}
+		if abs, err := filepath.Abs(configPath); err == nil {
+			configPath = abs
 		}
 		project := config.Project{
 			ConfigPath: configPath,
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/openshift-knative/deviate/pkg/config"
+	pkgerrors "github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/log"
+)
+
+// ValidateConfig will load the configuration, and report all the problems
+// found in it, without touching any branch.
+func ValidateConfig(
+	logger log.Logger,
+	out io.Writer,
+	projectFactory func() config.Project,
+) error {
+	st, err := newState("config", logger, projectFactory)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	_, err = fmt.Fprintln(out, "Configuration is valid:", st.Project.ConfigPath)
+	return err //nolint:wrapcheck
+}
+
+// ShowConfig will print the effective configuration, telling the origin of
+// each of its values.
+func ShowConfig(
+	logger log.Logger,
+	out io.Writer,
+	format OutputFormat,
+	projectFactory func() config.Project,
+) error {
+	if format != OutputYAML && format != OutputJSON {
+		return fmt.Errorf("%w: %q", ErrUnsupportedOutputFormat, format)
+	}
+	st, err := newState("config", logger, projectFactory)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	if format == OutputYAML {
+		return st.Config.WriteYAML(out) //nolint:wrapcheck
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return pkgerrors.Wrap(enc.Encode(struct {
+		Config  *config.Config `json:"config"`
+		Origins config.Origins `json:"origins"`
+	}{st.Config, st.Config.Origins()}), config.ErrCantShowConfig)
+}
+
+// ConfigSchema will print the JSON Schema of the configuration file.
+func ConfigSchema(out io.Writer) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(config.Schema()) //nolint:wrapcheck
+}
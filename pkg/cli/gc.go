@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"github.com/openshift-knative/deviate/pkg/config"
+	pkgerrors "github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/log"
+	"github.com/openshift-knative/deviate/pkg/sync"
+)
+
+// GarbageCollect will close the stale sync PRs, and delete the stale sync
+// branches.
+func GarbageCollect(logger log.Logger, projectFactory func() config.Project) error {
+	st, err := newState("gc", logger, projectFactory)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	op := sync.Operation{State: st}
+	return pkgerrors.Wrap(op.GarbageCollect(), sync.ErrSyncFailed)
+}
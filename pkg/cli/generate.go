@@ -0,0 +1,14 @@
+package cli
+
+import (
+	"github.com/openshift-knative/deviate/pkg/sync"
+)
+
+// GenerateCommand is the hidden command, the images are generated with.
+const GenerateCommand = sync.GenerateCommand
+
+// GenerateImages generates the images with the dockerfilegen params read
+// from the file. It's run by the synchronization in a separate process.
+func GenerateImages(paramsFile string) error {
+	return sync.GenerateDockerfiles(paramsFile) //nolint:wrapcheck
+}
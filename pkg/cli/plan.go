@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/openshift-knative/deviate/pkg/config"
+	pkgerrors "github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/log"
+	"github.com/openshift-knative/deviate/pkg/sync"
+)
+
+// ErrUnsupportedOutputFormat when the requested output format isn't supported.
+var ErrUnsupportedOutputFormat = pkgerrors.New("unsupported output format")
+
+// OutputFormat is a format of the command output.
+type OutputFormat string
+
+const (
+	// OutputText is a human-readable output.
+	OutputText OutputFormat = "text"
+	// OutputJSON is a machine-readable JSON output.
+	OutputJSON OutputFormat = "json"
+	// OutputYAML is a YAML output, annotated with comments.
+	OutputYAML OutputFormat = "yaml"
+)
+
+// Plan will print the actions the synchronization would perform, without
+// performing them.
+func Plan(
+	logger log.Logger,
+	out io.Writer,
+	format OutputFormat,
+	projectFactory func() config.Project,
+) error {
+	if format != OutputText && format != OutputJSON {
+		return fmt.Errorf("%w: %q", ErrUnsupportedOutputFormat, format)
+	}
+	st, err := newState("plan", logger, projectFactory)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	op := sync.Operation{State: st}
+	plan, err := op.Plan()
+	if err != nil {
+		return pkgerrors.Wrap(err, sync.ErrSyncFailed)
+	}
+	if format == OutputJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return pkgerrors.Wrap(enc.Encode(plan), sync.ErrSyncFailed)
+	}
+	return plan.WriteText(out) //nolint:wrapcheck
+}
@@ -2,12 +2,14 @@ package cli
 
 import (
 	"errors"
+	"path/filepath"
 
 	"github.com/openshift-knative/deviate/pkg/config"
 	pkgerrors "github.com/openshift-knative/deviate/pkg/errors"
 	"github.com/openshift-knative/deviate/pkg/git"
 	"github.com/openshift-knative/deviate/pkg/log"
 	"github.com/openshift-knative/deviate/pkg/log/color"
+	"github.com/openshift-knative/deviate/pkg/provenance"
 	"github.com/openshift-knative/deviate/pkg/state"
 	"github.com/openshift-knative/deviate/pkg/sync"
 )
@@ -15,27 +17,57 @@ import (
 // ErrConfigurationIsInvalid when configuration is invalid.
 var ErrConfigurationIsInvalid = errors.New("configuration is invalid")
 
+// SyncOptions control the synchronization.
+type SyncOptions struct {
+	// Resume continues the interrupted synchronization.
+	Resume bool
+}
+
 // Sync will perform synchronization to upstream branches.
-func Sync(logger log.Logger, projectFactory func() config.Project) error {
+func Sync(logger log.Logger, projectFactory func() config.Project, opts SyncOptions) error {
+	st, err := newState("sync", logger, projectFactory)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	op := sync.Operation{
+		State:  st,
+		RunID:  provenance.NewRunID(),
+		Resume: opts.Resume,
+	}
+	return pkgerrors.Wrap(op.Run(), sync.ErrSyncFailed)
+}
+
+func newState(
+	label string,
+	logger log.Logger,
+	projectFactory func() config.Project,
+) (state.State, error) {
 	color.SetupMode()
 	st := state.New(log.LabeledLogger{
-		Label: color.Green("[deviate:sync]"),
+		Label: color.Green("[deviate:" + label + "]"),
 		Logger: log.TimedLogger{
 			Logger: logger,
 		},
 	})
-	defer st.Close()
 	project, err := git.NewProject(projectFactory(), st)
 	if err != nil {
-		return pkgerrors.Wrap(err, ErrConfigurationIsInvalid)
+		st.Close()
+		return st, pkgerrors.Wrap(err, ErrConfigurationIsInvalid)
 	}
-	cfg, err := config.New(project.Project, st, project.Repository())
+	repo := project.Repository()
+	cfg, err := config.New(project.Project, st, repo)
 	if err != nil {
-		return pkgerrors.Wrap(err, ErrConfigurationIsInvalid)
+		st.Close()
+		return st, pkgerrors.Wrap(err, ErrConfigurationIsInvalid)
+	}
+	if !filepath.IsAbs(cfg.DockerfileGen.RootDir) {
+		cfg.DockerfileGen.RootDir = filepath.Join(project.Path, cfg.DockerfileGen.RootDir)
 	}
+	repo.Auth = cfg.Auth
+	repo.Commits = cfg.Commits
 	st.Project = &project.Project
-	st.Repository = project.Repository()
+	st.Repository = repo
 	st.Config = &cfg
-	op := sync.Operation{State: st}
-	return pkgerrors.Wrap(op.Run(), sync.ErrSyncFailed)
+	return st, nil
 }
@@ -1,17 +1,31 @@
 package config
 
 import (
+	"github.com/openshift-knative/deviate/pkg/config/git"
 	"github.com/openshift-knative/deviate/pkg/files"
 	"github.com/openshift-knative/hack/pkg/dockerfilegen"
 )
 
+// DefaultRemoteAuth returns the default authentication of a remote.
+func DefaultRemoteAuth() RemoteAuth {
+	return RemoteAuth{
+		HTTP: HTTPAuth{
+			Username: "x-access-token",
+			Netrc:    true,
+		},
+		SSH: SSHAuth{User: "git"},
+	}
+}
+
 // newDefaults creates a new default configuration.
 func newDefaults(project Project) Config {
 	const (
 		releaseTemplate = "release-{{ .Major }}.{{ .Minor }}"
-		releaseSearch   = `^release-(\d+)\.(\d+)$`
+		releaseSearch   = `^release-(?P<major>\d+)\.(?P<minor>\d+)$`
 	)
+	remoteAuth := DefaultRemoteAuth()
 	return Config{
+		Concurrency: 1,
 		DeleteFromUpstream: files.Filters{
 			Include: []string{
 				".github/workflows/knative-*.y?ml",
@@ -34,10 +48,16 @@ func newDefaults(project Project) Config {
 			},
 		},
 		Tags: Tags{
-			RefSpec: "v*",
+			RefSpec:           "v*",
+			ImageTemplate:     "knative-v{{ .Version }}",
+			ImageNextTemplate: "knative-next",
 		},
 		ResyncReleases: ResyncReleases{
 			NumberOf: 6, //nolint:mnd
+			OnConflict: OnConflict{
+				Strategy: git.MergeMarkers,
+				Label:    "conflicts",
+			},
 		},
 		Messages: Messages{
 			TriggerCI: ":robot: Synchronize branch `%s` to " +
@@ -47,8 +67,17 @@ func newDefaults(project Project) Config {
 				" a CI.",
 			ApplyForkFiles:  ":open_file_folder: Apply fork specific files",
 			ImagesGenerated: ":vhs: Images generated",
+			MergeConflicts: ":warning: Changes from `upstream/%s` couldn't be " +
+				"merged automatically. The conflicts were resolved with the `%s` " +
+				"strategy, and need to be reviewed in the following files:",
+			StalePR: ":broom: Closing, as the `%s` branch is no longer " +
+				"re-synced with upstream.",
 		},
 		SyncLabels: []string{"kind/sync-fork-to-upstream"},
+		Auth: Auth{
+			Upstream:   remoteAuth,
+			Downstream: remoteAuth,
+		},
 		DockerfileGen: DockerfileGen{
 			Params: dockerfilegen.DefaultParams(project.Path),
 		},
@@ -0,0 +1,172 @@
+package config_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/config"
+	"github.com/openshift-knative/deviate/pkg/config/git"
+	"github.com/openshift-knative/deviate/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_Extends(t *testing.T) {
+	tmp := t.TempDir()
+	writeConfig(t, tmp, "org/base.yaml", `extends:
+  - repository: https://github.com/example/config.git
+    ref: v1
+    path: deviate/base.yaml
+syncLabels:
+  $append: [kind/org]
+copyFromMidstream:
+  exclude: [docs/**]
+messages:
+  applyForkFiles: ":file_folder: Org files"
+`)
+	configPath := writeConfig(t, tmp, ".deviate.yaml", `extends:
+  - path: org/base.yaml
+upstream: https://github.com/example/project.git
+syncLabels:
+  $append: [kind/project]
+copyFromMidstream:
+  exclude:
+    $replace: [docs/api/**]
+`)
+	informer := remoteFiles{
+		"https://github.com/example/config.git//deviate/base.yaml@v1": `
+messages:
+  imagesGenerated: ":vhs: Org images"
+dryRun: true
+`,
+	}
+
+	cfg, err := config.New(config.Project{Path: tmp, ConfigPath: configPath},
+		log.TestingLogger{T: t}, informer)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"kind/sync-fork-to-upstream", "kind/org", "kind/project",
+	}, cfg.SyncLabels)
+	assert.Equal(t, []string{"docs/api/**"}, cfg.CopyFromMidstream.Exclude)
+	assert.Equal(t, []string{"**"}, cfg.CopyFromMidstream.Include)
+	assert.Equal(t, ":file_folder: Org files", cfg.ApplyForkFiles)
+	assert.Equal(t, ":vhs: Org images", cfg.ImagesGenerated)
+	assert.Equal(t, "main", cfg.Main)
+	assert.True(t, cfg.DryRun)
+	assert.Equal(t, []config.Extend{{Path: "org/base.yaml"}}, cfg.Extends)
+	origins := cfg.Origins()
+	assert.Equal(t, config.OriginFile, origins["syncLabels"])
+	assert.Equal(t, config.OriginExtends+" "+config.Origin(path.Join(tmp, "org/base.yaml")),
+		origins["messages.applyForkFiles"])
+	assert.Equal(t, config.OriginExtends+
+		" https://github.com/example/config.git//deviate/base.yaml@v1",
+		origins["dryRun"])
+}
+
+func TestNew_ExtendsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  error
+	}{{
+		name: "cycle",
+		files: map[string]string{
+			".deviate.yaml": "extends: [{path: a.yaml}]\n",
+			"a.yaml":        "extends: [{path: b.yaml}]\n",
+			"b.yaml":        "extends: [{path: a.yaml}]\n",
+		},
+		want: config.ErrConfigFileHaveInvalidFormat,
+	}, {
+		name: "missing",
+		files: map[string]string{
+			".deviate.yaml": "extends: [{path: a.yaml}]\n",
+		},
+		want: config.ErrConfigFileCantBeRead,
+	}, {
+		name: "unknown directive",
+		files: map[string]string{
+			".deviate.yaml": "syncLabels: {$prepend: [a]}\n",
+		},
+		want: config.ErrInvalidDirective,
+	}, {
+		name: "remote without reader",
+		files: map[string]string{
+			".deviate.yaml": "extends: [{repository: https://example.org/c.git, path: a.yaml}]\n",
+		},
+		want: config.ErrConfigFileCantBeRead,
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			for name, content := range tc.files {
+				writeConfig(t, tmp, name, content)
+			}
+			project := config.Project{Path: tmp, ConfigPath: path.Join(tmp, ".deviate.yaml")}
+
+			_, err := config.New(project, log.TestingLogger{T: t}, noopInformer{})
+
+			require.ErrorIs(t, err, tc.want)
+		})
+	}
+}
+
+func TestNew_ExtendsValidation(t *testing.T) {
+	tmp := t.TempDir()
+	basePath := writeConfig(t, tmp, "org/base.yaml", `branches:
+  searches:
+    upstreamReleases: '^release-(\d+$'
+copyFromMidstream:
+  include: ["[a-"]
+`)
+	configPath := writeConfig(t, tmp, ".deviate.yaml", `extends:
+  - path: org/base.yaml
+upstream: https://github.com/example/project.git
+resyncReleases:
+  onConflict:
+    strategy: ours
+`)
+	project := config.Project{Path: tmp, ConfigPath: configPath}
+
+	_, err := config.New(project, log.TestingLogger{T: t}, noopInformer{})
+
+	require.ErrorIs(t, err, config.ErrConfigFileHaveInvalidFormat)
+	var verr *config.ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, configPath, verr.File)
+	got := make(map[string]string, len(verr.Problems))
+	for _, p := range verr.Problems {
+		got[p.Path] = p.Location(verr.File)
+	}
+	assert.Equal(t, map[string]string{
+		"branches.searches.upstreamReleases": basePath + ":3",
+		"copyFromMidstream.include[0]":       basePath + ":5",
+		"resyncReleases.onConflict.strategy": configPath + ":6",
+	}, got)
+	assert.Contains(t, err.Error(), basePath+":3: branches.searches.upstreamReleases: "+
+		"error parsing regexp: missing closing )")
+}
+
+func writeConfig(tb testing.TB, dir, name, content string) string {
+	tb.Helper()
+	pth := path.Join(dir, name)
+	require.NoError(tb, os.MkdirAll(path.Dir(pth), 0o755))
+	require.NoError(tb, os.WriteFile(pth, []byte(content), 0o600))
+	return pth
+}
+
+// remoteFiles hold the contents of the remote files, by their locations.
+type remoteFiles map[string]string
+
+func (r remoteFiles) Remote(name string) (string, error) {
+	return name, nil
+}
+
+func (r remoteFiles) ReadRemoteFile(file git.RemoteFile) ([]byte, error) {
+	content, ok := r[file.URL+"//"+file.Path+"@"+file.Ref]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
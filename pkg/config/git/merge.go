@@ -0,0 +1,41 @@
+package git
+
+import "github.com/go-git/go-git/v5/plumbing"
+
+// MergeStrategy tells how to resolve changes that can't be merged
+// automatically.
+type MergeStrategy string
+
+const (
+	// MergeFail doesn't resolve the conflicts, the merge fails instead.
+	MergeFail MergeStrategy = ""
+	// MergeMarkers commits the conflicting files with conflict markers.
+	MergeMarkers MergeStrategy = "markers"
+	// MergeTheirs resolves the conflicts in favor of the merged branch.
+	MergeTheirs MergeStrategy = "theirs"
+)
+
+// MergeOptions controls how the branches are merged.
+type MergeOptions struct {
+	Strategy MergeStrategy
+	// Message decorates the message of the merge commit, if set. The merged
+	// commit is the one resolved after the remote was fetched.
+	Message func(message string, merged plumbing.Hash) string
+}
+
+// MergeOption configures the MergeOptions.
+type MergeOption func(*MergeOptions)
+
+// WithMergeStrategy sets the strategy used to resolve conflicts.
+func WithMergeStrategy(strategy MergeStrategy) MergeOption {
+	return func(o *MergeOptions) {
+		o.Strategy = strategy
+	}
+}
+
+// WithMessage sets the decorator of the merge commit message.
+func WithMessage(decorate func(message string, merged plumbing.Hash) string) MergeOption {
+	return func(o *MergeOptions) {
+		o.Message = decorate
+	}
+}
@@ -0,0 +1,31 @@
+package git
+
+import "github.com/go-git/go-git/v5/plumbing"
+
+// PushOptions controls how the reference is pushed. By default, only
+// fast-forward updates of the remote reference are pushed.
+type PushOptions struct {
+	// Force allows the remote reference to be overwritten.
+	Force bool
+	// Lease is the hash the remote reference is expected to point to, as it
+	// was observed before. Zero hash expects the reference to be absent. The
+	// remote reference is overwritten only if it still matches the lease.
+	Lease *plumbing.Hash
+}
+
+// PushOption configures the PushOptions.
+type PushOption func(*PushOptions)
+
+// WithForce allows the remote reference to be overwritten.
+func WithForce() PushOption {
+	return func(o *PushOptions) {
+		o.Force = true
+	}
+}
+
+// WithLease protects the remote reference with a lease on the expected hash.
+func WithLease(expected plumbing.Hash) PushOption {
+	return func(o *PushOptions) {
+		o.Lease = &expected
+	}
+}
@@ -16,14 +16,37 @@ type RemoteURLInformer interface {
 	Remote(name string) (string, error)
 }
 
+// RemoteFile is a file at the ref of a remote repository. The ref is a branch
+// or a tag, or the default branch of the repository, if empty.
+type RemoteFile struct {
+	URL  string
+	Ref  string
+	Path string
+}
+
+// RemoteFileReader will read a file of a remote repository, without
+// checking it out.
+type RemoteFileReader interface {
+	ReadRemoteFile(file RemoteFile) ([]byte, error)
+}
+
 // Repository contains operations on underlying GIT repo.
 type Repository interface {
 	RemoteLister
 	RemoteURLInformer
+	RemoteFileReader
 	Fetch(remote Remote) error
 	Checkout(remote Remote, branch string) Checkout
-	Push(remote Remote, refname plumbing.ReferenceName) error
+	Push(remote Remote, refname plumbing.ReferenceName, opts ...PushOption) error
 	DeleteBranch(branch string) error
+	DeleteRemoteBranch(remote Remote, branch string) error
 	CommitChanges(message string) (*object.Commit, error)
-	Merge(remote *Remote, branch string) error
+	Merge(remote *Remote, branch string, opts ...MergeOption) error
+	ApplyPatch(patchFile string) error
+	Changelog(since, until string, limit int) ([]*object.Commit, error)
+	ResolveRevision(rev plumbing.Revision) (*plumbing.Hash, error)
+	Head() (*plumbing.Reference, error)
+	SwitchTo(ref *plumbing.Reference) error
+	Clone(dir string) (Repository, error)
+	GitDir() (string, error)
 }
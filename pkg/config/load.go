@@ -1,12 +1,14 @@
 package config
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
 
 	"github.com/openshift-knative/deviate/pkg/config/git"
-	"github.com/openshift-knative/deviate/pkg/log"
 	"sigs.k8s.io/yaml"
 )
 
@@ -17,21 +19,174 @@ var (
 	ErrConfigFileHaveInvalidFormat = errors.New("config file have invalid format")
 )
 
-func (c *Config) load(
-	project Project,
-	log log.Logger,
-	informer git.RemoteURLInformer,
-) error {
-	bytes, err := os.ReadFile(project.ConfigPath)
+// Extend is a configuration file, the configuration extends. The local path
+// is relative to the extending file. With the repository, the path is a file
+// in that repository, at the ref, which is a branch or a tag, or the default
+// branch, if empty. Extended files are merged in order, before the
+// extending file.
+type Extend struct {
+	Path       string `json:"path"`
+	Repository string `json:"repository"`
+	Ref        string `json:"ref"`
+}
+
+// source locates a configuration file, in a remote repository, if the URL is
+// given, or locally, otherwise.
+type source git.RemoteFile
+
+func (s source) String() string {
+	if s.URL == "" {
+		return s.Path
+	}
+	if s.Ref == "" {
+		return s.URL + "//" + s.Path
+	}
+	return s.URL + "//" + s.Path + "@" + s.Ref
+}
+
+// resolve returns the source of the file, extended by this one. Relative
+// paths stay in the same repository.
+func (s source) resolve(e Extend) source {
+	switch {
+	case e.Repository != "":
+		return source{URL: e.Repository, Ref: e.Ref, Path: path.Clean(e.Path)}
+	case s.URL != "":
+		return source{URL: s.URL, Ref: s.Ref, Path: path.Join(path.Dir(s.Path), e.Path)}
+	case filepath.IsAbs(e.Path):
+		return source{Path: e.Path}
+	default:
+		return source{Path: filepath.Join(filepath.Dir(s.Path), e.Path)}
+	}
+}
+
+// layer is a configuration file, merged onto the configuration.
+type layer struct {
+	source source
+	bytes  []byte
+	doc    document
+}
+
+type loader struct {
+	reader  git.RemoteFileReader
+	loading map[string]bool
+	layers  []layer
+}
+
+// loadLayers reads the configuration file, and the files it extends,
+// recursively. The layers are ordered, so the extended files come before
+// the extending ones.
+func loadLayers(project Project, informer git.RemoteURLInformer) ([]layer, error) {
+	l := &loader{loading: make(map[string]bool)}
+	l.reader, _ = informer.(git.RemoteFileReader)
+	if err := l.load(source{Path: project.ConfigPath}); err != nil {
+		return nil, err
+	}
+	return l.layers, nil
+}
+
+func (l *loader) load(src source) error {
+	key := src.String()
+	if l.loading[key] {
+		return fmt.Errorf("%s - %w: extends itself", key,
+			ErrConfigFileHaveInvalidFormat)
+	}
+	bytes, err := l.read(src)
+	if err != nil {
+		return err
+	}
+	doc := make(document)
+	if err = yaml.Unmarshal(bytes, &doc); err != nil {
+		return fmt.Errorf("%s - %w: %w", key, ErrConfigFileHaveInvalidFormat, err)
+	}
+	var head struct {
+		Extends []Extend `json:"extends"`
+	}
+	if err = yaml.Unmarshal(bytes, &head); err != nil {
+		return fmt.Errorf("%s - %w: %w", key, ErrConfigFileHaveInvalidFormat, err)
+	}
+	l.loading[key] = true
+	for i, e := range head.Extends {
+		if e.Path == "" {
+			return fmt.Errorf("%s - %w: extends[%d]: path is required", key,
+				ErrConfigFileHaveInvalidFormat, i)
+		}
+		if err = l.load(src.resolve(e)); err != nil {
+			return err
+		}
+	}
+	delete(l.loading, key)
+	l.layers = append(l.layers, layer{source: src, bytes: bytes, doc: doc})
+	return nil
+}
+
+func (l *loader) read(src source) ([]byte, error) {
+	if src.URL == "" {
+		bytes, err := os.ReadFile(src.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%s - %w: %w", src.Path,
+				ErrConfigFileCantBeRead, err)
+		}
+		return bytes, nil
+	}
+	if l.reader == nil {
+		return nil, fmt.Errorf("%s - %w: remote files can't be read",
+			src, ErrConfigFileCantBeRead)
+	}
+	bytes, err := l.reader.ReadRemoteFile(git.RemoteFile(src))
 	if err != nil {
-		return fmt.Errorf("%s - %w: %w", project.ConfigPath,
-			ErrConfigFileCantBeRead, err)
+		return nil, fmt.Errorf("%s - %w: %w", src, ErrConfigFileCantBeRead, err)
 	}
-	err = yaml.Unmarshal(bytes, c)
+	return bytes, nil
+}
+
+// apply merges the layers onto the configuration, recording the origins of
+// the values. The last layer is the configuration file itself.
+func (c *Config) apply(project Project, layers []layer, t *tracker) error {
+	merged, err := toDocument(*c)
 	if err != nil {
-		return fmt.Errorf("%s - %w: %w", project.ConfigPath,
-			ErrConfigFileHaveInvalidFormat, err)
+		return err
 	}
+	for i, l := range layers {
+		merged, err = merge(merged, l.doc, nil)
+		if err != nil {
+			return fmt.Errorf("%s - %w: %w", l.source,
+				ErrConfigFileHaveInvalidFormat, err)
+		}
+		next := newDefaults(project)
+		if err = fromDocument(merged, &next); err != nil {
+			return fmt.Errorf("%s - %w: %w", l.source,
+				ErrConfigFileHaveInvalidFormat, err)
+		}
+		*c = next
+		t.record(*c, layerOrigin(layers, i), inFile(l.bytes))
+	}
+	return nil
+}
 
-	return c.loadFromGit(log, informer)
+// layerOrigin returns the origin of the values given in the layer.
+func layerOrigin(layers []layer, i int) Origin {
+	if i < len(layers)-1 {
+		return OriginExtends + " " + Origin(layers[i].source.String())
+	}
+	return OriginFile
+}
+
+func toDocument(c Config) (document, error) {
+	bytes, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConfigFileHaveInvalidFormat, err)
+	}
+	doc := make(document)
+	if err = json.Unmarshal(bytes, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConfigFileHaveInvalidFormat, err)
+	}
+	return doc, nil
+}
+
+func fromDocument(doc document, c *Config) error {
+	bytes, err := json.Marshal(doc)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	return json.Unmarshal(bytes, c) //nolint:wrapcheck
 }
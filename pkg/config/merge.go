@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openshift-knative/deviate/pkg/errors"
+)
+
+// ErrInvalidDirective when a merge directive can't be applied.
+var ErrInvalidDirective = errors.New("invalid merge directive")
+
+// Merge directives of the lists. A plain list replaces the base one, like
+// with the replace directive.
+const (
+	directiveAppend  = "$append"
+	directiveReplace = "$replace"
+)
+
+// releasesKey is the key of the release overrides.
+const releasesKey = "releases"
+
+// document is a configuration file, as unmarshalled into JSON values.
+type document = map[string]any
+
+// merge merges the overlay document onto the base one. The mappings are
+// merged recursively, and the other values are replaced, unless the overlay
+// is a merge directive, like:
+//
+//	syncLabels:
+//	  $append: [ kind/fork ]
+func merge(base, overlay document, field fieldPath) (document, error) {
+	result := make(document, len(base)+len(overlay))
+	for key, value := range base {
+		result[key] = value
+	}
+	for key, value := range overlay {
+		// The keys are matched case-insensitively, as when the file is
+		// unmarshalled.
+		baseKey := key
+		for k := range base {
+			if strings.EqualFold(k, key) {
+				baseKey = k
+				break
+			}
+		}
+		if len(field) == 0 && strings.EqualFold(key, releasesKey) {
+			result[baseKey] = mergeReleases(base[baseKey], value)
+			continue
+		}
+		merged, err := mergeValue(base[baseKey], value, field.child(baseKey))
+		if err != nil {
+			return nil, err
+		}
+		result[baseKey] = merged
+	}
+	return result, nil
+}
+
+// mergeReleases merges the release overrides. The overrides are kept as
+// given, with their directives, to be merged when a release is synced, so an
+// override replaces the base one of the same version range.
+func mergeReleases(base, overlay any) any {
+	baseDoc, isBaseDoc := base.(document)
+	overlayDoc, isOverlayDoc := overlay.(document)
+	if !isBaseDoc || !isOverlayDoc {
+		return overlay
+	}
+	result := make(document, len(baseDoc)+len(overlayDoc))
+	for key, value := range baseDoc {
+		result[key] = value
+	}
+	for key, value := range overlayDoc {
+		result[key] = value
+	}
+	return result
+}
+
+func mergeValue(base, overlay any, field fieldPath) (any, error) {
+	overlayDoc, ok := overlay.(document)
+	if !ok {
+		return overlay, nil
+	}
+	if directive, items, isDirective := asDirective(overlayDoc); isDirective {
+		return applyDirective(base, directive, items, field)
+	}
+	baseDoc, ok := base.(document)
+	if !ok {
+		baseDoc = document{}
+	}
+	return merge(baseDoc, overlayDoc, field)
+}
+
+func asDirective(doc document) (string, any, bool) {
+	if len(doc) != 1 {
+		return "", nil, false
+	}
+	for key, value := range doc {
+		if strings.HasPrefix(key, "$") {
+			return key, value, true
+		}
+	}
+	return "", nil, false
+}
+
+func applyDirective(base any, directive string, items any, field fieldPath) (any, error) {
+	list, ok := items.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s of %s should be a list",
+			ErrInvalidDirective, directive, field)
+	}
+	switch directive {
+	case directiveReplace:
+		return list, nil
+	case directiveAppend:
+		if base == nil {
+			return list, nil
+		}
+		baseList, isList := base.([]any)
+		if !isList {
+			return nil, fmt.Errorf("%w: %s of %s, which isn't a list",
+				ErrInvalidDirective, directive, field)
+		}
+		return append(append([]any{}, baseList...), list...), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown %s of %s",
+			ErrInvalidDirective, directive, field)
+	}
+}
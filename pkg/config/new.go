@@ -12,17 +12,29 @@ func New(
 	informer git.RemoteURLInformer,
 ) (Config, error) {
 	c := newDefaults(project)
-	err := c.load(project, log, informer)
+	t := newTracker(c)
+	layers, err := loadLayers(project, informer)
 	if err != nil {
 		return Config{}, err
 	}
+	err = c.apply(project, layers, t)
+	if err != nil {
+		return Config{}, err
+	}
+	err = c.loadFromGit(log, informer)
+	if err != nil {
+		return Config{}, err
+	}
+	t.record(c, OriginGit, nil)
 	err = c.overrides()
 	if err != nil {
 		return Config{}, err
 	}
-	err = c.validate()
+	t.record(c, OriginEnv, nil)
+	err = c.validate(project, layers, t.origins)
 	if err != nil {
 		return Config{}, err
 	}
+	c.origins = t.origins
 	return c, nil
 }
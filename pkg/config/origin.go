@@ -0,0 +1,150 @@
+package config
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/openshift-knative/deviate/pkg/errors"
+	"gopkg.in/yaml.v3"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// ErrCantShowConfig when the effective configuration can't be printed.
+var ErrCantShowConfig = errors.New("can't show the configuration")
+
+// Origin is a source of a configuration value.
+type Origin string
+
+const (
+	// OriginDefault is a built-in default value.
+	OriginDefault Origin = "default"
+	// OriginFile is a value given in the configuration file.
+	OriginFile Origin = "file"
+	// OriginExtends is a value given in a file, the configuration extends.
+	// The origin is followed by the location of the file.
+	OriginExtends Origin = "extends"
+	// OriginGit is a value taken from the git remotes of the project.
+	OriginGit Origin = "git"
+	// OriginEnv is a value overridden with a DEVIATE_* environment variable.
+	OriginEnv Origin = "env"
+)
+
+// Origins tell which source has set the configuration values, by their
+// paths, like branches.searches.upstreamReleases. Values other than structs,
+// like lists and maps, are tracked as a whole.
+type Origins map[string]Origin
+
+// Origins returns the origin of each value of the configuration.
+func (c Config) Origins() Origins {
+	return c.origins
+}
+
+// WriteYAML writes the configuration as YAML, with the origin of each value
+// as a comment.
+func (c Config) WriteYAML(w io.Writer) error {
+	bytes, err := sigsyaml.Marshal(c)
+	if err != nil {
+		return errors.Wrap(err, ErrCantShowConfig)
+	}
+	var doc yaml.Node
+	if err = yaml.Unmarshal(bytes, &doc); err != nil {
+		return errors.Wrap(err, ErrCantShowConfig)
+	}
+	if len(doc.Content) > 0 {
+		annotate(doc.Content[0], nil, c.origins)
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2) //nolint:mnd
+	if err = enc.Encode(&doc); err != nil {
+		return errors.Wrap(err, ErrCantShowConfig)
+	}
+	return errors.Wrap(enc.Close(), ErrCantShowConfig)
+}
+
+func annotate(node *yaml.Node, base fieldPath, origins Origins) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		field := base.child(key.Value)
+		origin, ok := origins[field.String()]
+		switch {
+		case !ok:
+			annotate(value, field, origins)
+		case value.Kind == yaml.ScalarNode:
+			value.LineComment = string(origin)
+		default:
+			key.LineComment = string(origin)
+		}
+	}
+}
+
+// tracker records the origins of the values, as the configuration is
+// loaded from the subsequent sources.
+type tracker struct {
+	origins Origins
+	last    map[string]leaf
+}
+
+type leaf struct {
+	field fieldPath
+	value any
+}
+
+func newTracker(c Config) *tracker {
+	t := &tracker{origins: make(Origins)}
+	t.record(c, OriginDefault, nil)
+	return t
+}
+
+// record marks the values, that were changed or given since the last
+// record, as coming from the origin.
+func (t *tracker) record(c Config, origin Origin, given func(fieldPath) bool) {
+	current := make(map[string]leaf, len(t.last))
+	leaves(reflect.ValueOf(c), nil, current)
+	for key, l := range current {
+		prev, ok := t.last[key]
+		if !ok || !reflect.DeepEqual(prev.value, l.value) ||
+			(given != nil && given(l.field)) {
+			t.origins[key] = origin
+		}
+	}
+	t.last = current
+}
+
+func leaves(v reflect.Value, base fieldPath, into map[string]leaf) {
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := jsonName(f)
+		switch name {
+		case "-":
+			continue
+		case "":
+			leaves(v.Field(i), base, into)
+			continue
+		}
+		field := base.child(name)
+		if f.Type.Kind() == reflect.Struct {
+			leaves(v.Field(i), field, into)
+			continue
+		}
+		into[field.String()] = leaf{field: field, value: v.Field(i).Interface()}
+	}
+}
+
+// inFile tells if the field is given in the configuration file, even if it
+// has the default value.
+func inFile(bytes []byte) func(fieldPath) bool {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(bytes, &doc); err != nil {
+		return nil
+	}
+	return func(field fieldPath) bool {
+		return lineOf(&doc, field) > 0
+	}
+}
@@ -0,0 +1,52 @@
+package config_test
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/config"
+	"github.com/openshift-knative/deviate/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const originsConfigYaml = `upstream: https://github.com/example/project.git
+branches:
+  main: main
+  releaseNext: next
+resyncReleases:
+  numberOf: 3
+`
+
+func TestNew_Origins(t *testing.T) {
+	t.Setenv("DEVIATE_NUMBEROF", "4")
+	tmp := t.TempDir()
+	configPath := path.Join(tmp, ".deviate.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(originsConfigYaml), 0o600))
+	project := config.Project{
+		Path:       tmp,
+		ConfigPath: configPath,
+	}
+
+	cfg, err := config.New(project, log.TestingLogger{T: t}, noopInformer{})
+	require.NoError(t, err)
+
+	origins := cfg.Origins()
+	assert.Equal(t, config.OriginFile, origins["upstream"])
+	assert.Equal(t, config.OriginGit, origins["downstream"])
+	assert.Equal(t, config.OriginFile, origins["branches.main"])
+	assert.Equal(t, config.OriginFile, origins["branches.releaseNext"])
+	assert.Equal(t, config.OriginEnv, origins["resyncReleases.numberOf"])
+	assert.Equal(t, config.OriginDefault, origins["branches.checkPrPrefix"])
+	assert.Equal(t, config.OriginDefault, origins["copyFromMidstream.include"])
+	assert.Equal(t, config.OriginDefault, origins["dockerfileGen.skip"])
+
+	var out bytes.Buffer
+	require.NoError(t, cfg.WriteYAML(&out))
+	assert.Contains(t, out.String(), "\n  releaseNext: next # file\n")
+	assert.Contains(t, out.String(), "\n  numberOf: 4 # env\n")
+	assert.Contains(t, out.String(), "\ndownstream: downstream # git\n")
+	assert.Contains(t, out.String(), "\n  include: # default\n    - '**'\n")
+}
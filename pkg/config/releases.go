@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/openshift-knative/deviate/pkg/semver"
+)
+
+// ReleaseNextKey is the key of the overrides of the release-next branch.
+const ReleaseNextKey = "next"
+
+// repositoryKeys are the keys of the configuration, that apply to the project
+// repository as a whole, so they can't be overridden per release.
+var repositoryKeys = []string{"auth", "commits"}
+
+// ForRelease returns the configuration with the overrides of the upstream
+// release version applied.
+func (c Config) ForRelease(v semver.Version) (Config, error) {
+	return c.override(func(key string) (bool, error) {
+		if key == ReleaseNextKey {
+			return false, nil
+		}
+		r, err := semver.ParseRange(key)
+		if err != nil {
+			return false, err //nolint:wrapcheck
+		}
+		return r.Contains(v), nil
+	})
+}
+
+// ForReleaseNext returns the configuration with the overrides of the
+// release-next branch applied.
+func (c Config) ForReleaseNext() (Config, error) {
+	return c.override(func(key string) (bool, error) {
+		return key == ReleaseNextKey, nil
+	})
+}
+
+// override merges the overrides with matching keys onto the configuration,
+// in the order of the keys.
+func (c Config) override(matches func(key string) (bool, error)) (Config, error) {
+	var doc document
+	for _, key := range slices.Sorted(maps.Keys(c.Releases)) {
+		field := fieldPath{"releases", key}
+		ok, err := matches(key)
+		if err != nil {
+			return Config{}, fmt.Errorf("%w: %s: %w",
+				ErrConfigFileHaveInvalidFormat, field, err)
+		}
+		if !ok {
+			continue
+		}
+		if doc == nil {
+			if doc, err = toDocument(c); err != nil {
+				return Config{}, err
+			}
+		}
+		if doc, err = merge(doc, c.Releases[key], field); err != nil {
+			return Config{}, fmt.Errorf("%w: %w", ErrConfigFileHaveInvalidFormat, err)
+		}
+	}
+	if doc == nil {
+		return c, nil
+	}
+	result := Config{origins: c.origins}
+	if err := fromDocument(doc, &result); err != nil {
+		return Config{}, fmt.Errorf("%w: %w", ErrConfigFileHaveInvalidFormat, err)
+	}
+	result.Releases = c.Releases
+	return result, nil
+}
@@ -0,0 +1,85 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/config"
+	"github.com/openshift-knative/deviate/pkg/log"
+	"github.com/openshift-knative/deviate/pkg/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_ForRelease(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := writeConfig(t, tmp, ".deviate.yaml", `upstream: https://github.com/example/project.git
+syncLabels: [kind/sync]
+releases:
+  "<1.12":
+    dockerfileGen:
+      skip: true
+    syncLabels:
+      $append: [kind/legacy]
+  ">=1.10 <1.14":
+    branches:
+      releaseTemplates:
+        downstream: "release-v{{ .Major }}.{{ .Minor }}"
+    syncLabels:
+      $append: [kind/maintained]
+  next:
+    syncLabels:
+      $replace: [kind/next]
+`)
+	cfg, err := config.New(config.Project{Path: tmp, ConfigPath: configPath},
+		log.TestingLogger{T: t}, noopInformer{})
+	require.NoError(t, err)
+
+	legacy, err := cfg.ForRelease(semver.Version{Major: 1, Minor: 11})
+	require.NoError(t, err)
+	assert.True(t, legacy.DockerfileGen.Skip)
+	assert.Equal(t, "release-v{{ .Major }}.{{ .Minor }}", legacy.ReleaseTemplates.Downstream)
+	assert.Equal(t, []string{"kind/sync", "kind/legacy", "kind/maintained"}, legacy.SyncLabels)
+
+	current, err := cfg.ForRelease(semver.Version{Major: 1, Minor: 14})
+	require.NoError(t, err)
+	assert.False(t, current.DockerfileGen.Skip)
+	assert.Equal(t, cfg.ReleaseTemplates, current.ReleaseTemplates)
+	assert.Equal(t, []string{"kind/sync"}, current.SyncLabels)
+
+	next, err := cfg.ForReleaseNext()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kind/next"}, next.SyncLabels)
+	assert.Equal(t, []string{"kind/sync"}, cfg.SyncLabels)
+}
+
+func TestNew_InvalidReleases(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := writeConfig(t, tmp, ".deviate.yaml", `upstream: https://github.com/example/project.git
+releases:
+  "~1.12":
+    dryRun: true
+  ">=1.14":
+    resyncReleases:
+      onConflict:
+        strategy: ours
+  "<1.10":
+    commits:
+      signOff: true
+`)
+	project := config.Project{Path: tmp, ConfigPath: configPath}
+
+	_, err := config.New(project, log.TestingLogger{T: t}, noopInformer{})
+
+	require.ErrorIs(t, err, config.ErrConfigFileHaveInvalidFormat)
+	var verr *config.ValidationError
+	require.ErrorAs(t, err, &verr)
+	got := make(map[string]int, len(verr.Problems))
+	for _, p := range verr.Problems {
+		got[p.Path] = p.Line
+	}
+	assert.Equal(t, map[string]int{
+		`releases["~1.12"]`: 3,
+		`releases[">=1.14"].resyncReleases.onConflict.strategy`: 8,
+		`releases["<1.10"].commits`:                             10,
+	}, got)
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/openshift-knative/deviate/pkg/metadata"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is a subset of the JSON Schema, enough to describe the
+// configuration file.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	OneOf                []*JSONSchema          `json:"oneOf,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+}
+
+// Schema generates the JSON Schema of the configuration file, with the
+// defaults of the configuration.
+func Schema() *JSONSchema {
+	s := schemaOf(reflect.ValueOf(newDefaults(Project{})))
+	s.Schema = schemaDraft
+	s.Title = metadata.Name + " configuration"
+	// The release overrides are partial configurations, without defaults.
+	override := schemaOf(reflect.ValueOf(Config{}))
+	delete(override.Properties, "extends")
+	delete(override.Properties, "releases")
+	for _, key := range repositoryKeys {
+		delete(override.Properties, key)
+	}
+	s.Properties["releases"] = &JSONSchema{
+		Type:                 "object",
+		AdditionalProperties: override,
+	}
+	return s
+}
+
+func schemaOf(v reflect.Value) *JSONSchema {
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		s := &JSONSchema{
+			Type:                 "object",
+			Properties:           make(map[string]*JSONSchema),
+			AdditionalProperties: false,
+		}
+		addProperties(s, v)
+		return s
+	case reflect.Slice:
+		s := listSchema(schemaOf(reflect.New(v.Type().Elem()).Elem()))
+		if v.Len() > 0 {
+			s.Default = v.Interface()
+		}
+		return s
+	case reflect.Map:
+		return &JSONSchema{
+			Type:                 "object",
+			AdditionalProperties: schemaOf(reflect.New(v.Type().Elem()).Elem()),
+		}
+	case reflect.String:
+		return scalar("string", v)
+	case reflect.Bool:
+		return scalar("boolean", v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return scalar("integer", v)
+	default:
+		return &JSONSchema{}
+	}
+}
+
+// listSchema describes a list, that's given either directly, or with a merge
+// directive.
+func listSchema(items *JSONSchema) *JSONSchema {
+	list := &JSONSchema{Type: "array", Items: items}
+	return &JSONSchema{OneOf: []*JSONSchema{list, {
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			directiveAppend:  list,
+			directiveReplace: list,
+		},
+		AdditionalProperties: false,
+	}}}
+}
+
+func scalar(typ string, v reflect.Value) *JSONSchema {
+	s := &JSONSchema{Type: typ}
+	if !v.IsZero() {
+		s.Default = v.Interface()
+	}
+	return s
+}
+
+func addProperties(s *JSONSchema, v reflect.Value) {
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := jsonName(f)
+		switch name {
+		case "-":
+			continue
+		case "":
+			addProperties(s, v.Field(i))
+			continue
+		}
+		p := schemaOf(v.Field(i))
+		p.Enum = enumOf(f.Tag.Get("valid"))
+		s.Properties[name] = p
+	}
+}
+
+// enumOf returns the values of the in() validator.
+func enumOf(tag string) []string {
+	for _, rule := range strings.Split(tag, ",") {
+		if values, ok := strings.CutPrefix(rule, "in("); ok {
+			return strings.Split(strings.TrimSuffix(values, ")"), "|")
+		}
+	}
+	return nil
+}
@@ -1,12 +1,14 @@
 package config
 
 import (
+	"github.com/openshift-knative/deviate/pkg/config/git"
 	"github.com/openshift-knative/deviate/pkg/files"
 	"github.com/openshift-knative/hack/pkg/dockerfilegen"
 )
 
 // Config for a deviate to operate.
 type Config struct {
+	Extends            []Extend      `json:"extends"            ignored:"true"`
 	Upstream           string        `json:"upstream"           valid:"required"`
 	Downstream         string        `json:"downstream"         valid:"required"`
 	DryRun             bool          `json:"dryRun"`
@@ -14,22 +16,64 @@ type Config struct {
 	DeleteFromUpstream files.Filters `json:"deleteFromUpstream" valid:"required"`
 	SyncLabels         []string      `json:"syncLabels"         valid:"required"`
 	DockerfileGen      DockerfileGen `json:"dockerfileGen"`
+	Forge              Forge         `json:"forge"`
+	PullRequests       PullRequests  `json:"pullRequests"`
+	Auth               Auth          `json:"auth"`
+	Commits            Commits       `json:"commits"`
 	ResyncReleases     `json:"resyncReleases"`
 	Branches           `json:"branches"`
 	Tags               `json:"tags"`
 	Messages           `json:"messages"`
+	// Concurrency is the number of releases processed at once. Each of the
+	// concurrently processed releases is worked on in a temporary clone of
+	// the project.
+	Concurrency int `json:"concurrency" valid:"range(1|64)"`
+	// Releases override the configuration for the upstream releases within
+	// the version ranges, like "<1.12" or ">=1.12 <1.14", and for the
+	// release-next branch with the "next" key. The overrides of all the
+	// matching keys are applied, in the order of the keys.
+	Releases map[string]map[string]any `json:"releases" ignored:"true" valid:"-"`
+
+	// origins of the values, as loaded by New.
+	origins Origins
 }
 
 // ResyncReleases holds configuration for resyncing past releases.
+// When Cleanup is set, the sync PRs and branches of releases falling out of
+// the resync window are closed and deleted at the end of the sync.
 type ResyncReleases struct {
-	Enabled  bool `json:"enabled"`
-	NumberOf int  `json:"numberOf"`
+	Enabled    bool `json:"enabled"`
+	NumberOf   int  `json:"numberOf"`
+	Cleanup    bool `json:"cleanup"`
+	OnConflict `json:"onConflict"`
+}
+
+// OnConflict holds configuration of how to handle releases, that can't be
+// merged with upstream automatically. When OpenPR is set, the merge is
+// committed, resolving the conflicts with given strategy, and a PR with given
+// label is opened.
+type OnConflict struct {
+	OpenPR   bool              `json:"openPr"`
+	Strategy git.MergeStrategy `json:"strategy" valid:"in(markers|theirs)"`
+	Label    string            `json:"label"`
 }
 
-// Tags holds configuration for tags.
+// Tags holds configuration for tags. The image templates are used to tag the
+// generated images, and have access to .Major, .Minor, .Patch, .Pre, .Version
+// and .Upstream (name of the upstream project).
 type Tags struct {
-	Synchronize bool   `json:"synchronize"`
-	RefSpec     string `json:"refSpec"     valid:"required"`
+	Synchronize       bool   `json:"synchronize"`
+	RefSpec           string `json:"refSpec"           valid:"required"`
+	ImageTemplate     string `json:"imageTemplate"     valid:"required"`
+	ImageNextTemplate string `json:"imageNextTemplate" valid:"required"`
+}
+
+// TagData is available to the image tag templates.
+type TagData struct {
+	Major, Minor, Patch int
+	Pre                 string
+	Version             string
+	Upstream            string
 }
 
 // Messages holds messages that are used to commit changes and create PRs.
@@ -38,6 +82,8 @@ type Messages struct {
 	TriggerCIBody   string `json:"triggerCiBody"   valid:"required"`
 	ApplyForkFiles  string `json:"applyForkFiles"  valid:"required"`
 	ImagesGenerated string `json:"imagesGenerated" valid:"required"`
+	MergeConflicts  string `json:"mergeConflicts"  valid:"required"`
+	StalePR         string `json:"stalePr"         valid:"required"`
 }
 
 // Branches holds configuration for branches.
@@ -48,6 +94,30 @@ type Branches struct {
 	SkipCheckPr      bool   `json:"skipCheckPr"`
 	ReleaseTemplates `json:"releaseTemplates"`
 	Searches         `json:"searches"`
+	VersionMapping   `json:"versionMapping"`
+	// MinimumRelease is the lowest upstream release, that is mirrored and
+	// resynced. Lower releases are ignored.
+	MinimumRelease string `json:"minimumRelease"`
+	// SkipReleases are upstream releases that are never mirrored nor resynced.
+	SkipReleases []string `json:"skipReleases"`
+	// EndOfLife are upstream releases that are mirrored, but never resynced.
+	EndOfLife []string `json:"endOfLife"`
+}
+
+// VersionMapping translates upstream release versions into downstream ones,
+// for products that have their own version line. Explicit mappings, from
+// upstream to downstream version (ex.: "1.14": "1.33"), take precedence over
+// the offset.
+type VersionMapping struct {
+	Offset   VersionOffset     `json:"offset"`
+	Explicit map[string]string `json:"explicit"`
+}
+
+// VersionOffset is added to the upstream release version to get the
+// downstream one.
+type VersionOffset struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
 }
 
 // ReleaseTemplates contains templates for release names.
@@ -56,12 +126,118 @@ type ReleaseTemplates struct {
 	Downstream string `json:"downstream" valid:"required"`
 }
 
-// Searches contains regular expressions used to search for branches.
+// Searches contains regular expressions used to search for branches. The
+// version of a release is taken from the major, minor, patch and pre named
+// groups, or from the first two groups, if the expression has no named groups.
 type Searches struct {
 	UpstreamReleases   string `json:"upstreamReleases"   valid:"required"`
 	DownstreamReleases string `json:"downstreamReleases" valid:"required"`
 }
 
+// Forge holds configuration of the service hosting the downstream repository.
+// The type is detected from the downstream host, and the API URL is derived
+// from it, unless given.
+type Forge struct {
+	Type string `json:"type" valid:"in(github|gitlab|gitea)"`
+	URL  string `json:"url"`
+}
+
+// Auth holds the authentication of the upstream, and the downstream remotes.
+type Auth struct {
+	Upstream   RemoteAuth `json:"upstream"`
+	Downstream RemoteAuth `json:"downstream"`
+}
+
+// Remote returns the authentication of the remote with given name.
+func (a Auth) Remote(name string) RemoteAuth {
+	switch name {
+	case "upstream":
+		return a.Upstream
+	case "downstream":
+		return a.Downstream
+	default:
+		return RemoteAuth{}
+	}
+}
+
+// RemoteAuth holds the authentication of a remote. It can be overridden with
+// DEVIATE_AUTH_<REMOTE>_<HTTP|SSH>_<FIELD> variables, like
+// DEVIATE_AUTH_DOWNSTREAM_SSH_KEYS.
+type RemoteAuth struct {
+	HTTP HTTPAuth `json:"http"`
+	SSH  SSHAuth  `json:"ssh"`
+}
+
+// HTTPAuth holds the token authentication of HTTP(S) remotes. The token is
+// taken from the TokenEnv variable, the DEVIATE_<REMOTE>_TOKEN variable, the
+// git credential helper, or the netrc file, whichever has it first. The
+// credential helper runs the git binary on each remote operation, so it's
+// only asked, if it's enabled for the remote.
+type HTTPAuth struct {
+	// Username sent along the token, if the source doesn't tell one.
+	Username         string `json:"username"`
+	TokenEnv         string `json:"tokenEnv"`
+	CredentialHelper bool   `json:"credentialHelper"`
+	Netrc            bool   `json:"netrc"`
+}
+
+// SSHAuth holds the key authentication of SSH remotes. Without keys, the SSH
+// agent is used, if it's available, or the default keys from ~/.ssh. The
+// host keys are strictly verified against the KnownHosts file, or against
+// the default known hosts files, if it isn't given.
+type SSHAuth struct {
+	// User to authenticate as, if the remote URL doesn't tell one.
+	User string `json:"user"`
+	// Keys are paths to the private keys.
+	Keys []string `json:"keys"`
+	// PassphraseEnv is a name of the variable holding the passphrase of the
+	// encrypted keys.
+	PassphraseEnv string `json:"passphraseEnv"`
+	KnownHosts    string `json:"knownHosts"`
+}
+
+// Commits holds configuration of the commits made by deviate. Without the
+// author, and the committer, the identity is taken from the git config.
+type Commits struct {
+	Author    Identity `json:"author"`
+	Committer Identity `json:"committer"`
+	// SignOff adds the Signed-off-by trailer of the committer.
+	SignOff bool    `json:"signOff"`
+	Signing Signing `json:"signing"`
+}
+
+// Identity of a commit author, or committer.
+type Identity struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Signing holds configuration of the commit signatures. Key is a path to an
+// armored OpenPGP private key for the gpg format, or to an SSH private key
+// for the ssh format. Commits aren't signed, if the format is empty.
+type Signing struct {
+	Format        string `json:"format"        valid:"in(gpg|ssh)"`
+	Key           string `json:"key"`
+	PassphraseEnv string `json:"passphraseEnv"`
+}
+
+// PullRequests holds settings of the sync PRs, per kind of the PR.
+type PullRequests struct {
+	ReleaseNext PullRequest `json:"releaseNext"`
+	Releases    PullRequest `json:"releases"`
+}
+
+// PullRequest holds settings of a kind of sync PRs. AutoMerge is a merge
+// method (merge, squash or rebase) used to merge the PR when its checks pass.
+type PullRequest struct {
+	Reviewers     []string `json:"reviewers"`
+	TeamReviewers []string `json:"teamReviewers"`
+	Assignees     []string `json:"assignees"`
+	Milestone     string   `json:"milestone"`
+	Draft         bool     `json:"draft"`
+	AutoMerge     string   `json:"autoMerge"     valid:"in(merge|squash|rebase)"`
+}
+
 // DockerfileGen wraps dockerfilegen.Params adding a skip param.
 type DockerfileGen struct {
 	dockerfilegen.Params
@@ -1,15 +1,439 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
 
 	valid "github.com/asaskevich/govalidator"
+	"github.com/gobwas/glob"
+	"github.com/openshift-knative/deviate/pkg/files"
+	"github.com/openshift-knative/deviate/pkg/semver"
+	"gopkg.in/yaml.v3"
 )
 
-func (c *Config) validate() error {
-	ok, err := valid.ValidateStruct(c)
-	if !ok {
-		return fmt.Errorf("%w: %w", ErrConfigFileHaveInvalidFormat, err)
+var (
+	errVersionNotCaptured = errors.New("the expression should capture " +
+		"the major, and the minor version")
+	errRepositoryKey = errors.New("applies to the whole repository, " +
+		"and can't be overridden per release")
+)
+
+// Problem is a misconfiguration of a single field. The file is the one that
+// sets the field, which is either the configuration file, or a file it
+// extends. The file is empty, and the line is zero, if the field isn't set in
+// any file, like for defaults and values overridden with the environment
+// variables.
+type Problem struct {
+	Path    string
+	File    string
+	Line    int
+	Message string
+
+	field fieldPath
+}
+
+// Location returns the file, and the line of the problem. The given file is
+// returned for problems that aren't located in any file.
+func (p Problem) Location(file string) string {
+	if p.File != "" {
+		file = p.File
+	}
+	if p.Line > 0 {
+		return file + ":" + strconv.Itoa(p.Line)
+	}
+	return file
+}
+
+// ValidationError holds all the problems found in the configuration, which
+// is read from the file.
+type ValidationError struct {
+	File     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(ErrConfigFileHaveInvalidFormat.Error())
+	for _, p := range e.Problems {
+		sb.WriteString(fmt.Sprintf("\n  %s: %s: %s",
+			p.Location(e.File), p.Path, p.Message))
+	}
+	return sb.String()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrConfigFileHaveInvalidFormat
+}
+
+// validate checks all the fields up front, compiling the regular
+// expressions, globs and templates, so the misconfiguration is reported
+// before any branch is touched.
+func (c *Config) validate(project Project, layers []layer, origins Origins) error {
+	v := &validator{}
+	v.check(c)
+	v.overrides(c)
+	if len(v.problems) == 0 {
+		return nil
+	}
+	locate(layers, origins, v.problems)
+	slices.SortStableFunc(v.problems, byLocation)
+	return &ValidationError{File: project.ConfigPath, Problems: v.problems}
+}
+
+type validator struct {
+	problems []Problem
+}
+
+func (v *validator) check(c *Config) {
+	v.fields(c)
+	v.searches(c.Searches)
+	v.filters(fieldPath{"copyFromMidstream"}, c.CopyFromMidstream)
+	v.filters(fieldPath{"deleteFromUpstream"}, c.DeleteFromUpstream)
+	v.templates(c.ReleaseTemplates, c.Tags)
+	v.versions(c.Branches)
+}
+
+// overrides checks the release overrides, and the configuration of each
+// of them. Problems already present in the configuration aren't repeated.
+func (v *validator) overrides(c *Config) {
+	reported := make(map[string]bool, len(v.problems))
+	for _, p := range v.problems {
+		reported[p.Path] = true
+	}
+	for _, key := range slices.Sorted(maps.Keys(c.Releases)) {
+		field := fieldPath{"releases", key}
+		if key != ReleaseNextKey {
+			if _, err := semver.ParseRange(key); err != nil {
+				v.add(field, err)
+				continue
+			}
+		}
+		for _, name := range repositoryKeys {
+			if _, ok := c.Releases[key][name]; ok {
+				v.add(field.child(name), errRepositoryKey)
+			}
+		}
+		overridden, err := c.override(func(k string) (bool, error) {
+			return k == key, nil
+		})
+		if err != nil {
+			v.add(field, err)
+			continue
+		}
+		sub := &validator{}
+		sub.check(&overridden)
+		for _, p := range sub.problems {
+			if !reported[p.Path] {
+				p.field = field.child(p.field...)
+				p.Path = p.field.String()
+				v.problems = append(v.problems, p)
+			}
+		}
+	}
+}
+
+func (v *validator) add(field fieldPath, err error) {
+	if err == nil {
+		return
+	}
+	v.problems = append(v.problems, Problem{
+		Path:    field.String(),
+		Message: err.Error(),
+		field:   field,
+	})
+}
+
+// fields checks the valid tags of the configuration.
+func (v *validator) fields(c *Config) {
+	_, err := valid.ValidateStruct(c)
+	for _, err = range flatten(err) {
+		var verr valid.Error
+		if !errors.As(err, &verr) {
+			v.add(nil, err)
+			continue
+		}
+		goPath := append(append([]string{}, verr.Path...), verr.Name)
+		v.add(jsonPath(reflect.TypeOf(*c), goPath), verr.Err)
+	}
+}
+
+func (v *validator) searches(s Searches) {
+	base := fieldPath{"branches", "searches"}
+	v.add(base.child("upstreamReleases"), checkSearch(s.UpstreamReleases))
+	v.add(base.child("downstreamReleases"), checkSearch(s.DownstreamReleases))
+}
+
+func (v *validator) filters(base fieldPath, f files.Filters) {
+	v.globs(base.child("include"), f.Include)
+	v.globs(base.child("exclude"), f.Exclude)
+}
+
+func (v *validator) globs(base fieldPath, patterns []string) {
+	for i, p := range patterns {
+		_, err := glob.Compile(p, '/')
+		v.add(base.child(i), err)
+	}
+}
+
+func (v *validator) templates(rt ReleaseTemplates, tags Tags) {
+	release := semver.Version{Major: 1, Minor: 2}
+	tag := TagData{Major: 1, Minor: 2, Version: "1.2", Upstream: "upstream"}
+	releases := fieldPath{"branches", "releaseTemplates"}
+	v.add(releases.child("upstream"), checkTemplate(rt.Upstream, release))
+	v.add(releases.child("downstream"), checkTemplate(rt.Downstream, release))
+	v.add(fieldPath{"tags", "imageTemplate"}, checkTemplate(tags.ImageTemplate, tag))
+	v.add(fieldPath{"tags", "imageNextTemplate"}, checkTemplate(tags.ImageNextTemplate, tag))
+}
+
+func (v *validator) versions(b Branches) {
+	base := fieldPath{"branches"}
+	if b.MinimumRelease != "" {
+		_, err := semver.Parse(b.MinimumRelease)
+		v.add(base.child("minimumRelease"), err)
+	}
+	v.releases(base.child("skipReleases"), b.SkipReleases)
+	v.releases(base.child("endOfLife"), b.EndOfLife)
+	explicit := base.child("versionMapping", "explicit")
+	for _, up := range slices.Sorted(maps.Keys(b.Explicit)) {
+		if _, err := semver.Parse(up); err != nil {
+			v.add(explicit.child(up), err)
+			continue
+		}
+		_, err := semver.Parse(b.Explicit[up])
+		v.add(explicit.child(up), err)
+	}
+}
+
+func (v *validator) releases(base fieldPath, versions []string) {
+	for i, ver := range versions {
+		_, err := semver.Parse(ver)
+		v.add(base.child(i), err)
+	}
+}
+
+// checkSearch compiles the expression, and checks it captures the version,
+// as expected by the release listing.
+func checkSearch(expr string) error {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	if re.SubexpIndex("major") >= 0 {
+		if re.SubexpIndex("minor") < 0 {
+			return errVersionNotCaptured
+		}
+		return nil
+	}
+	const versionGroups = 2
+	if re.NumSubexp() < versionGroups {
+		return errVersionNotCaptured
 	}
 	return nil
 }
+
+// checkTemplate parses the template, and executes it with the sample data,
+// to find references to unknown fields.
+func checkTemplate(tpl string, data any) error {
+	t, err := template.New("").Option("missingkey=error").Parse(tpl)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	return t.Execute(io.Discard, data) //nolint:wrapcheck
+}
+
+func flatten(err error) []error {
+	var errs valid.Errors
+	if !errors.As(err, &errs) {
+		if err == nil {
+			return nil
+		}
+		return []error{err}
+	}
+	result := make([]error, 0, len(errs))
+	for _, e := range errs {
+		result = append(result, flatten(e)...)
+	}
+	return result
+}
+
+// fieldPath is a path of a field in the configuration file. The elements are
+// either keys of a mapping (string), or indexes of a sequence (int).
+type fieldPath []any
+
+func (p fieldPath) child(elems ...any) fieldPath {
+	return append(append(fieldPath{}, p...), elems...)
+}
+
+func (p fieldPath) String() string {
+	var sb strings.Builder
+	for _, elem := range p {
+		switch e := elem.(type) {
+		case int:
+			sb.WriteString(fmt.Sprintf("[%d]", e))
+		case string:
+			if strings.ContainsAny(e, ". ") {
+				sb.WriteString(fmt.Sprintf("[%q]", e))
+				continue
+			}
+			if sb.Len() > 0 {
+				sb.WriteString(".")
+			}
+			sb.WriteString(e)
+		}
+	}
+	return sb.String()
+}
+
+// jsonPath translates the path of the govalidator error into the path of the
+// configuration file keys.
+func jsonPath(t reflect.Type, goPath []string) fieldPath {
+	result := make(fieldPath, 0, len(goPath))
+	for _, name := range goPath {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			break
+		}
+		f, ok := fieldByName(t, name)
+		if !ok {
+			break
+		}
+		if key := jsonName(f); key != "" {
+			result = append(result, key)
+		}
+		t = f.Type
+	}
+	return result
+}
+
+// fieldByName finds the field by its Go name, or by its key, as govalidator
+// names the failed fields after their keys.
+func fieldByName(t reflect.Type, name string) (reflect.StructField, bool) {
+	if f, ok := t.FieldByName(name); ok {
+		return f, true
+	}
+	return t.FieldByNameFunc(func(goName string) bool {
+		f, _ := t.FieldByName(goName)
+		return jsonName(f) == name
+	})
+}
+
+// jsonName returns the key of the field, or empty string for embedded
+// structs that are inlined.
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" && !f.Anonymous {
+		return f.Name
+	}
+	return name
+}
+
+// byLocation orders the problems by their files, and lines, leaving the ones
+// outside the configuration files last.
+func byLocation(a, b Problem) int {
+	switch {
+	case a.Line == b.Line && a.File == b.File:
+		return 0
+	case a.Line == 0:
+		return 1
+	case b.Line == 0:
+		return -1
+	case a.File != b.File:
+		return strings.Compare(a.File, b.File)
+	default:
+		return a.Line - b.Line
+	}
+}
+
+// locate sets the files, and the lines of the problems, if their fields are
+// given in the configuration file, or the files it extends. The file is the
+// layer, the origin of the field points to.
+func locate(layers []layer, origins Origins, problems []Problem) {
+	docs := make(map[Origin]*yaml.Node, len(layers))
+	files := make(map[Origin]string, len(layers))
+	for i, l := range layers {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(l.bytes, &doc); err != nil {
+			continue
+		}
+		origin := layerOrigin(layers, i)
+		docs[origin] = &doc
+		files[origin] = l.source.String()
+	}
+	for i := range problems {
+		origin := originOf(origins, problems[i].field)
+		doc, ok := docs[origin]
+		if !ok {
+			continue
+		}
+		if line := lineOf(doc, problems[i].field); line > 0 {
+			problems[i].File = files[origin]
+			problems[i].Line = line
+		}
+	}
+}
+
+// originOf returns the origin of the field, or of the closest parent, that
+// is tracked, as lists and maps are tracked as a whole.
+func originOf(origins Origins, field fieldPath) Origin {
+	for n := len(field); n > 0; n-- {
+		if origin, ok := origins[field[:n].String()]; ok {
+			return origin
+		}
+	}
+	return ""
+}
+
+func lineOf(node *yaml.Node, path fieldPath) int {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if len(path) == 0 {
+		return 0
+	}
+	var key *yaml.Node
+	for _, elem := range path {
+		if key, node = childNode(node, elem); node == nil {
+			return 0
+		}
+	}
+	// The mappings start below their keys, so the key is pointed to.
+	if key != nil && node.Kind == yaml.MappingNode {
+		return key.Line
+	}
+	return node.Line
+}
+
+// childNode returns the element of the node, and its key, if the node is a
+// mapping.
+func childNode(node *yaml.Node, elem any) (*yaml.Node, *yaml.Node) {
+	switch e := elem.(type) {
+	case int:
+		if node.Kind == yaml.SequenceNode && e < len(node.Content) {
+			return nil, node.Content[e]
+		}
+	case string:
+		if node.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		// The keys are matched case-insensitively, and the last one wins, as
+		// when the file is unmarshalled.
+		var key, found *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if strings.EqualFold(node.Content[i].Value, e) {
+				key, found = node.Content[i], node.Content[i+1]
+			}
+		}
+		return key, found
+	}
+	return nil, nil
+}
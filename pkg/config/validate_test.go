@@ -0,0 +1,93 @@
+package config_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/config"
+	"github.com/openshift-knative/deviate/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const invalidConfigYaml = `upstream: https://github.com/example/project.git
+copyFromMidstream:
+  include:
+    - "**"
+    - "[a-"
+branches:
+  searches:
+    upstreamReleases: '^release-(\d+$'
+    downstreamReleases: '^release-\d+'
+  releaseTemplates:
+    downstream: "release-{{ .Majr }}"
+  skipReleases:
+    - "1.2"
+    - nope
+  versionMapping:
+    explicit:
+      "1.14": x
+resyncReleases:
+  onConflict:
+    strategy: ours
+`
+
+func TestNew_Invalid(t *testing.T) {
+	t.Setenv("DEVIATE_IMAGENEXTTEMPLATE", "knative-{{ .Next }}")
+	tmp := t.TempDir()
+	configPath := path.Join(tmp, ".deviate.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(invalidConfigYaml), 0o600))
+	project := config.Project{
+		Path:       tmp,
+		ConfigPath: configPath,
+	}
+
+	_, err := config.New(project, log.TestingLogger{T: t}, noopInformer{})
+
+	require.ErrorIs(t, err, config.ErrConfigFileHaveInvalidFormat)
+	var verr *config.ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, configPath, verr.File)
+	got := make(map[string]int, len(verr.Problems))
+	for _, p := range verr.Problems {
+		got[p.Path] = p.Line
+	}
+	assert.Equal(t, map[string]int{
+		"copyFromMidstream.include[1]":             5,
+		"branches.searches.upstreamReleases":       8,
+		"branches.searches.downstreamReleases":     9,
+		"branches.releaseTemplates.downstream":     11,
+		"branches.skipReleases[1]":                 14,
+		`branches.versionMapping.explicit["1.14"]`: 17,
+		"resyncReleases.onConflict.strategy":       20,
+		"tags.imageNextTemplate":                   0,
+	}, got)
+	assert.Contains(t, err.Error(), configPath+":8: branches.searches.upstreamReleases: "+
+		"error parsing regexp: missing closing )")
+}
+
+func TestSchema(t *testing.T) {
+	s := config.Schema()
+
+	assert.Equal(t, "object", s.Type)
+	assert.Equal(t, false, s.AdditionalProperties)
+	branches := s.Properties["branches"]
+	require.NotNil(t, branches)
+	assert.Equal(t, "main", branches.Properties["main"].Default)
+	assert.Equal(t, "string", branches.Properties["searches"].Properties["upstreamReleases"].Type)
+	assert.Equal(t, "string", branches.Properties["versionMapping"].
+		Properties["explicit"].AdditionalProperties.(*config.JSONSchema).Type)
+	strategy := s.Properties["resyncReleases"].Properties["onConflict"].Properties["strategy"]
+	assert.Equal(t, []string{"markers", "theirs"}, strategy.Enum)
+	dockerfileGen := s.Properties["dockerfileGen"]
+	assert.Equal(t, "boolean", dockerfileGen.Properties["skip"].Type)
+	imagesFrom := dockerfileGen.Properties["images-from"]
+	require.Len(t, imagesFrom.OneOf, 2)
+	assert.Equal(t, "array", imagesFrom.OneOf[0].Type)
+	assert.Equal(t, "array", imagesFrom.OneOf[1].Properties["$append"].Type)
+	override := s.Properties["releases"].AdditionalProperties.(*config.JSONSchema)
+	assert.NotNil(t, override.Properties["syncLabels"])
+	assert.Nil(t, override.Properties["auth"])
+	assert.Nil(t, override.Properties["commits"])
+}
@@ -0,0 +1,226 @@
+package diff3
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Labels are used to annotate the conflict markers.
+type Labels struct {
+	Ours   string
+	Theirs string
+}
+
+// Conflict is a region of the text changed differently on both sides.
+type Conflict struct {
+	// Line is a 1-based line number of the region in the base text.
+	Line   int
+	Base   []string
+	Ours   []string
+	Theirs []string
+}
+
+// Result of the three-way merge.
+type Result struct {
+	// Content is the merged text, with conflict markers if there were conflicts.
+	Content string
+	// Theirs is the merged text, with conflicts resolved in favor of theirs.
+	Theirs    string
+	Conflicts []Conflict
+}
+
+// Clean returns true if the merge has no conflicts.
+func (r Result) Clean() bool {
+	return len(r.Conflicts) == 0
+}
+
+// Merge performs a line-based three-way merge of the ours and theirs texts,
+// both derived from the base text. Changes touching the same, or adjacent,
+// lines of the base text are reported as conflicts.
+func Merge(base, ours, theirs string, labels Labels) Result {
+	baseLines := splitLines(base)
+	oursEdits := edits(base, ours)
+	theirsEdits := edits(base, theirs)
+	m := merger{base: baseLines, labels: labels}
+	m.merge(oursEdits, theirsEdits)
+	return Result{
+		Content:   m.out.String(),
+		Theirs:    m.theirs.String(),
+		Conflicts: m.conflicts,
+	}
+}
+
+// edit replaces base lines [start, end) with lines.
+type edit struct {
+	start, end int
+	lines      []string
+}
+
+func edits(src, dst string) []edit {
+	result := make([]edit, 0)
+	pos := 0
+	var current *edit
+	flush := func() {
+		if current != nil {
+			result = append(result, *current)
+			current = nil
+		}
+	}
+	for _, d := range diff.Do(src, dst) {
+		lines := splitLines(d.Text)
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			flush()
+			pos += len(lines)
+		case diffmatchpatch.DiffDelete:
+			if current == nil {
+				current = &edit{start: pos, end: pos}
+			}
+			pos += len(lines)
+			current.end = pos
+		case diffmatchpatch.DiffInsert:
+			if current == nil {
+				current = &edit{start: pos, end: pos}
+			}
+			current.lines = append(current.lines, lines...)
+		}
+	}
+	flush()
+	return result
+}
+
+type merger struct {
+	base      []string
+	labels    Labels
+	out       strings.Builder
+	theirs    strings.Builder
+	conflicts []Conflict
+}
+
+func (m *merger) merge(ours, theirs []edit) {
+	pos := 0
+	for len(ours) > 0 || len(theirs) > 0 {
+		var groupOurs, groupTheirs []edit
+		start := nextGroupStart(ours, theirs)
+		end := start
+		for {
+			taken := false
+			if len(ours) > 0 && ours[0].start <= end {
+				groupOurs = append(groupOurs, ours[0])
+				end = max(end, ours[0].end)
+				ours = ours[1:]
+				taken = true
+			}
+			if len(theirs) > 0 && theirs[0].start <= end {
+				groupTheirs = append(groupTheirs, theirs[0])
+				end = max(end, theirs[0].end)
+				theirs = theirs[1:]
+				taken = true
+			}
+			if !taken {
+				break
+			}
+		}
+		m.write(m.base[pos:start]...)
+		m.resolve(start, end, groupOurs, groupTheirs)
+		pos = end
+	}
+	m.write(m.base[pos:]...)
+}
+
+func nextGroupStart(ours, theirs []edit) int {
+	switch {
+	case len(ours) == 0:
+		return theirs[0].start
+	case len(theirs) == 0:
+		return ours[0].start
+	default:
+		return min(ours[0].start, theirs[0].start)
+	}
+}
+
+func (m *merger) resolve(start, end int, ours, theirs []edit) {
+	oursLines := m.apply(start, end, ours)
+	theirsLines := m.apply(start, end, theirs)
+	switch {
+	case len(theirs) == 0:
+		m.write(oursLines...)
+	case len(ours) == 0:
+		m.write(theirsLines...)
+	case slices.Equal(oursLines, theirsLines):
+		m.write(oursLines...)
+	default:
+		m.conflicts = append(m.conflicts, Conflict{
+			Line:   start + 1,
+			Base:   m.base[start:end],
+			Ours:   oursLines,
+			Theirs: theirsLines,
+		})
+		for _, line := range theirsLines {
+			m.theirs.WriteString(line)
+		}
+		m.marker("<<<<<<<", m.labels.Ours)
+		m.writeOut(terminated(oursLines)...)
+		m.marker("=======", "")
+		m.writeOut(terminated(theirsLines)...)
+		m.marker(">>>>>>>", m.labels.Theirs)
+	}
+}
+
+func (m *merger) apply(start, end int, group []edit) []string {
+	result := make([]string, 0, end-start)
+	pos := start
+	for _, e := range group {
+		result = append(result, m.base[pos:e.start]...)
+		result = append(result, e.lines...)
+		pos = e.end
+	}
+	return append(result, m.base[pos:end]...)
+}
+
+func (m *merger) marker(marker, label string) {
+	if label != "" {
+		marker += " " + label
+	}
+	m.out.WriteString(marker + "\n")
+}
+
+// write writes the lines that are common to both merge results.
+func (m *merger) write(lines ...string) {
+	for _, line := range lines {
+		m.out.WriteString(line)
+		m.theirs.WriteString(line)
+	}
+}
+
+func (m *merger) writeOut(lines ...string) {
+	for _, line := range lines {
+		m.out.WriteString(line)
+	}
+}
+
+// terminated ensures the last line ends with a newline, so the following
+// conflict marker starts on its own line.
+func terminated(lines []string) []string {
+	if len(lines) == 0 || strings.HasSuffix(lines[len(lines)-1], "\n") {
+		return lines
+	}
+	result := make([]string, len(lines))
+	copy(result, lines)
+	result[len(result)-1] += "\n"
+	return result
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
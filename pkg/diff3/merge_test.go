@@ -0,0 +1,73 @@
+package diff3_test
+
+import (
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/diff3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	const base = "a\nb\nc\nd\ne\n"
+	labels := diff3.Labels{Ours: "ours", Theirs: "theirs"}
+	tcs := []struct {
+		name       string
+		ours       string
+		theirs     string
+		want       string
+		wantTheirs string
+		conflicts  int
+	}{{
+		name:   "unchanged",
+		ours:   base,
+		theirs: base,
+		want:   base,
+	}, {
+		name:   "one side changed",
+		ours:   base,
+		theirs: "a\nB\nc\nd\ne\n",
+		want:   "a\nB\nc\nd\ne\n",
+	}, {
+		name:   "distinct changes",
+		ours:   "A\nb\nc\nd\ne\n",
+		theirs: "a\nb\nc\nd\nE\n",
+		want:   "A\nb\nc\nd\nE\n",
+	}, {
+		name:   "same change",
+		ours:   "a\nb\nX\nd\ne\n",
+		theirs: "a\nb\nX\nd\ne\n",
+		want:   "a\nb\nX\nd\ne\n",
+	}, {
+		name:   "conflict",
+		ours:   "a\nb\nX\nd\ne\n",
+		theirs: "a\nb\nY\nd\ne\n",
+		want: "a\nb\n<<<<<<< ours\nX\n=======\nY\n" +
+			">>>>>>> theirs\nd\ne\n",
+		wantTheirs: "a\nb\nY\nd\ne\n",
+		conflicts:  1,
+	}, {
+		name:       "adjacent changes conflict",
+		ours:       "a\nB\nc\nd\ne\n",
+		theirs:     "a\nb\nC\nd\ne\n",
+		want:       "a\n<<<<<<< ours\nB\nc\n=======\nb\nC\n>>>>>>> theirs\nd\ne\n",
+		wantTheirs: "a\nb\nC\nd\ne\n",
+		conflicts:  1,
+	}, {
+		name:   "deletion and addition",
+		ours:   "b\nc\nd\ne\n",
+		theirs: "a\nb\nc\nd\ne\nf\n",
+		want:   "b\nc\nd\ne\nf\n",
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got := diff3.Merge(base, tc.ours, tc.theirs, labels)
+			assert.Equal(t, tc.want, got.Content)
+			assert.Len(t, got.Conflicts, tc.conflicts)
+			wantTheirs := tc.wantTheirs
+			if wantTheirs == "" {
+				wantTheirs = tc.want
+			}
+			assert.Equal(t, wantTheirs, got.Theirs)
+		})
+	}
+}
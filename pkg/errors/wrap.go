@@ -31,3 +31,9 @@ func New(text string) error {
 func Join(err ...error) error {
 	return errors.Join(err...)
 }
+
+// As finds the first error in err's chain that matches target, and if one is
+// found, sets target to that error value and returns true.
+func As(err error, target any) bool {
+	return errors.As(err, target)
+}
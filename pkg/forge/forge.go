@@ -0,0 +1,182 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/git"
+)
+
+var (
+	// ErrNotFound when there's no open request for given branches.
+	ErrNotFound = errors.New("request not found")
+	// ErrUnsupportedForge when the forge type isn't known.
+	ErrUnsupportedForge = errors.New("unsupported forge")
+	// ErrUnsupportedSetting when the forge doesn't support a request setting.
+	ErrUnsupportedSetting = errors.New("unsupported setting")
+)
+
+// Type of the forge.
+type Type string
+
+const (
+	// GitHub is github.com, or a GitHub Enterprise instance.
+	GitHub Type = "github"
+	// GitLab is gitlab.com, or a self-managed GitLab instance.
+	GitLab Type = "gitlab"
+	// Gitea is a Gitea, or Forgejo, instance.
+	Gitea Type = "gitea"
+)
+
+// Forge hosts the repository, and its pull (or merge) requests.
+type Forge interface {
+	// FindOpen finds an open request of the head branch into the base branch.
+	// ErrNotFound is returned if there's none.
+	FindOpen(ctx context.Context, head, base string) (*Request, error)
+	// Open opens a new request.
+	Open(ctx context.Context, req NewRequest) (*Request, error)
+	// Update changes the title, and the body of the request.
+	Update(ctx context.Context, number int, update Update) (*Request, error)
+	// AddLabels adds labels to the request.
+	AddLabels(ctx context.Context, number int, labels ...string) error
+	// Comment comments on the request.
+	Comment(ctx context.Context, number int, body string) error
+	// Close closes the request, without merging it.
+	Close(ctx context.Context, number int) error
+	// Configure requests reviews, assigns, sets the milestone, and enables
+	// auto-merge of the request, as given by the settings.
+	Configure(ctx context.Context, req *Request, settings Settings) error
+}
+
+// Request is a pull request, or a merge request in GitLab terms.
+type Request struct {
+	// ID is a forge specific global identifier, if the forge has one.
+	ID      string
+	Number  int
+	URL     string
+	Title   string
+	Body    string
+	Head    string
+	HeadSHA string
+	Base    string
+	Labels  []string
+}
+
+// NewRequest holds the data of the request to open.
+type NewRequest struct {
+	Title string
+	Body  string
+	Head  string
+	Base  string
+	Draft bool
+}
+
+// MergeMethod is a method used to merge the request.
+type MergeMethod string
+
+const (
+	// MergeCommit merges the request with a merge commit.
+	MergeCommit MergeMethod = "merge"
+	// Squash squashes the request into a single commit.
+	Squash MergeMethod = "squash"
+	// Rebase rebases the commits of the request onto the base branch.
+	Rebase MergeMethod = "rebase"
+)
+
+// Settings route the request to the right people, and control its merging.
+type Settings struct {
+	Reviewers     []string
+	TeamReviewers []string
+	Assignees     []string
+	Milestone     string
+	// AutoMerge enables the request to be merged when the checks pass, with
+	// the method. Empty method disables it.
+	AutoMerge MergeMethod
+}
+
+// Update holds the changes of the request. Nil fields are left unchanged.
+type Update struct {
+	Title *string
+	Body  *string
+}
+
+// Options select, and configure the forge.
+type Options struct {
+	// Type of the forge. Detected from the repository host, if empty.
+	Type Type
+	// URL of the forge API. Derived from the repository host, if empty.
+	URL string
+}
+
+// New creates the forge hosting the repository with given remote URL.
+func New(repository string, opts Options) (Forge, error) {
+	addr, err := git.ParseAddress(repository)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedForge, err)
+	}
+	typ := opts.Type
+	if typ == "" {
+		typ = Detect(addr.Host)
+	}
+	host := hostname(addr)
+	switch typ {
+	case GitHub:
+		return newGitHub(host, addr.Path, opts.URL), nil
+	case GitLab:
+		return newGitLab(host, addr.Path, opts.URL), nil
+	case Gitea:
+		return newGitea(host, addr.Path, opts.URL), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedForge, typ)
+	}
+}
+
+// Detect the forge type by the repository host. GitHub is assumed, if the
+// host doesn't tell otherwise.
+func Detect(host string) Type {
+	host = strings.ToLower(stripPort(stripUser(host)))
+	switch {
+	case strings.Contains(host, "gitlab"):
+		return GitLab
+	case strings.Contains(host, "gitea"), strings.Contains(host, "forgejo"),
+		host == "codeberg.org":
+		return Gitea
+	default:
+		return GitHub
+	}
+}
+
+// hostname returns the host of the forge, from the repository address. The
+// port of HTTP(S) addresses is kept, as the forge is served on it, while the
+// port of SSH addresses isn't the one of the forge.
+func hostname(addr *git.Address) string {
+	host := stripUser(addr.Host)
+	if addr.Type == git.AddressTypeHTTP &&
+		(addr.Protocol == "http" || addr.Protocol == "https") {
+		return host
+	}
+	return stripPort(host)
+}
+
+func stripUser(host string) string {
+	if _, h, ok := strings.Cut(host, "@"); ok {
+		return h
+	}
+	return host
+}
+
+func stripPort(host string) string {
+	if h, _, ok := strings.Cut(host, ":"); ok {
+		return h
+	}
+	return host
+}
+
+func baseURL(configured, host, path string) string {
+	if configured != "" {
+		return strings.TrimSuffix(configured, "/")
+	}
+	return "https://" + host + path
+}
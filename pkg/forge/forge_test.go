@@ -0,0 +1,234 @@
+package forge_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/forge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	tcs := map[string]forge.Type{
+		"github.com":            forge.GitHub,
+		"git@github.com":        forge.GitHub,
+		"gitlab.example.org":    forge.GitLab,
+		"gitlab.com:2222":       forge.GitLab,
+		"gitea.example.org":     forge.Gitea,
+		"codeberg.org":          forge.Gitea,
+		"git.example.org":       forge.GitHub,
+		"forgejo.example.org":   forge.Gitea,
+		"code.gitlab.internal":  forge.GitLab,
+		"github.enterprise.com": forge.GitHub,
+	}
+	for host, want := range tcs {
+		assert.Equal(t, want, forge.Detect(host), host)
+	}
+}
+
+func TestNew_Port(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/acme/fork/pulls", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, []any{})
+	})
+	srv := httptest.NewTLSServer(mux)
+	defer srv.Close()
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = srv.Client().Transport
+	t.Cleanup(func() {
+		http.DefaultClient.Transport = transport
+	})
+	// The API is derived from the repository address, with its port.
+	f, err := forge.New(srv.URL+"/acme/fork.git", forge.Options{Type: forge.Gitea})
+	require.NoError(t, err)
+
+	_, err = f.FindOpen(t.Context(), "ci/release-next", "release-next")
+	require.ErrorIs(t, err, forge.ErrNotFound)
+}
+
+func TestNew_Unsupported(t *testing.T) {
+	_, err := forge.New("https://example.org/acme/fork.git",
+		forge.Options{Type: "bitbucket"})
+	require.ErrorIs(t, err, forge.ErrUnsupportedForge)
+}
+
+func TestGitLab(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "s3cr3t")
+	var labels string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /projects/{project}/merge_requests", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "acme/group/fork", r.PathValue("project"))
+		assert.Equal(t, "s3cr3t", r.Header.Get("PRIVATE-TOKEN"))
+		assert.Equal(t, "ci/release-1.2", r.URL.Query().Get("source_branch"))
+		writeJSON(t, w, []any{})
+	})
+	mux.HandleFunc("POST /projects/{project}/merge_requests", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		writeJSON(t, w, map[string]any{
+			"iid":           3,
+			"web_url":       "https://gitlab.example.org/acme/group/fork/-/merge_requests/3",
+			"title":         body["title"],
+			"source_branch": body["source_branch"],
+			"target_branch": body["target_branch"],
+		})
+	})
+	mux.HandleFunc("PUT /projects/{project}/merge_requests/3", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		labels = body["add_labels"]
+		writeJSON(t, w, map[string]any{"iid": 3})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	f, err := forge.New("git@gitlab.example.org:acme/group/fork.git",
+		forge.Options{URL: srv.URL})
+	require.NoError(t, err)
+	ctx := t.Context()
+
+	_, err = f.FindOpen(ctx, "ci/release-1.2", "release-1.2")
+	require.ErrorIs(t, err, forge.ErrNotFound)
+	req, err := f.Open(ctx, forge.NewRequest{
+		Title: "Sync", Head: "ci/release-1.2", Base: "release-1.2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, req.Number)
+	assert.Equal(t, "ci/release-1.2", req.Head)
+	require.NoError(t, f.AddLabels(ctx, req.Number, "a", "b"))
+	assert.Equal(t, "a,b", labels)
+}
+
+func TestGitea(t *testing.T) {
+	t.Setenv("GITEA_TOKEN", "s3cr3t")
+	var comment, state string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/fork/pulls", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token s3cr3t", r.Header.Get("Authorization"))
+		writeJSON(t, w, []map[string]any{{
+			"number": 1,
+			"head":   map[string]string{"ref": "feature"},
+			"base":   map[string]string{"ref": "main"},
+		}, {
+			"number":   2,
+			"html_url": "https://gitea.example.org/acme/fork/pulls/2",
+			"head":     map[string]string{"ref": "ci/release-next", "sha": "abc"},
+			"base":     map[string]string{"ref": "release-next"},
+			"labels":   []map[string]string{{"name": "sync"}},
+		}})
+	})
+	mux.HandleFunc("POST /repos/acme/fork/issues/2/comments", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		comment = body["body"]
+		writeJSON(t, w, map[string]any{})
+	})
+	mux.HandleFunc("PATCH /repos/acme/fork/pulls/2", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		state = body["state"]
+		writeJSON(t, w, map[string]any{"number": 2})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	f, err := forge.New("https://gitea.example.org/acme/fork.git",
+		forge.Options{URL: srv.URL})
+	require.NoError(t, err)
+	ctx := t.Context()
+
+	req, err := f.FindOpen(ctx, "ci/release-next", "release-next")
+	require.NoError(t, err)
+	assert.Equal(t, &forge.Request{
+		Number:  2,
+		URL:     "https://gitea.example.org/acme/fork/pulls/2",
+		Head:    "ci/release-next",
+		HeadSHA: "abc",
+		Base:    "release-next",
+		Labels:  []string{"sync"},
+	}, req)
+	require.NoError(t, f.Comment(ctx, req.Number, "Updated"))
+	assert.Equal(t, "Updated", comment)
+	require.NoError(t, f.Close(ctx, req.Number))
+	assert.Equal(t, "closed", state)
+}
+
+func TestGitea_Configure(t *testing.T) {
+	t.Setenv("GITEA_TOKEN", "s3cr3t")
+	var title string
+	reviewers := map[string][]string{}
+	issue := map[string]any{}
+	merge := map[string]any{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/acme/fork/pulls", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		title = body["title"]
+		writeJSON(t, w, map[string]any{"number": 4})
+	})
+	mux.HandleFunc("POST /repos/acme/fork/pulls/4/requested_reviewers", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reviewers))
+		writeJSON(t, w, []any{})
+	})
+	mux.HandleFunc("GET /repos/acme/fork/milestones", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1.2", r.URL.Query().Get("name"))
+		writeJSON(t, w, []map[string]any{{"id": 7, "title": "1.2"}})
+	})
+	mux.HandleFunc("PATCH /repos/acme/fork/issues/4", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&issue))
+		writeJSON(t, w, map[string]any{})
+	})
+	mux.HandleFunc("POST /repos/acme/fork/pulls/4/merge", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&merge))
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	f, err := forge.New("https://gitea.example.org/acme/fork.git",
+		forge.Options{URL: srv.URL})
+	require.NoError(t, err)
+	ctx := t.Context()
+
+	req, err := f.Open(ctx, forge.NewRequest{
+		Title: "Sync", Head: "ci/release-1.2", Base: "release-1.2", Draft: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "WIP: Sync", title)
+	require.NoError(t, f.Configure(ctx, req, forge.Settings{
+		Reviewers:     []string{"alice"},
+		TeamReviewers: []string{"maintainers"},
+		Assignees:     []string{"bob"},
+		Milestone:     "1.2",
+		AutoMerge:     forge.Squash,
+	}))
+	assert.Equal(t, map[string][]string{
+		"reviewers":      {"alice"},
+		"team_reviewers": {"maintainers"},
+	}, reviewers)
+	assert.Equal(t, map[string]any{
+		"assignees": []any{"bob"},
+		"milestone": float64(7),
+	}, issue)
+	assert.Equal(t, map[string]any{
+		"Do":                        "squash",
+		"merge_when_checks_succeed": true,
+	}, merge)
+}
+
+func TestGitLab_Configure_Unsupported(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "s3cr3t")
+	f, err := forge.New("git@gitlab.example.org:acme/fork.git",
+		forge.Options{URL: "http://127.0.0.1:0"})
+	require.NoError(t, err)
+	err = f.Configure(t.Context(), &forge.Request{Number: 1}, forge.Settings{
+		TeamReviewers: []string{"maintainers"},
+	})
+	require.ErrorIs(t, err, forge.ErrUnsupportedSetting)
+}
+
+func writeJSON(tb testing.TB, w http.ResponseWriter, v any) {
+	tb.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	require.NoError(tb, json.NewEncoder(w).Encode(v))
+}
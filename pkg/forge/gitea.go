@@ -0,0 +1,210 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/rest"
+)
+
+// gitea talks to the Gitea v1 API, also served by Forgejo. The token is taken
+// from GITEA_TOKEN.
+type gitea struct {
+	client rest.Client
+	repo   string
+}
+
+func newGitea(host, repo, apiURL string) gitea {
+	header := http.Header{}
+	if token := os.Getenv("GITEA_TOKEN"); token != "" {
+		header.Set("Authorization", "token "+token)
+	}
+	owner, name, _ := strings.Cut(repo, "/")
+	return gitea{
+		client: rest.Client{
+			BaseURL:      baseURL(apiURL, host, "/api/v1"),
+			Header:       header,
+			ErrorMessage: giteaError,
+		},
+		repo: "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name),
+	}
+}
+
+type giteaPR struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	Head    struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+}
+
+func (pr giteaPR) request() *Request {
+	labels := make([]string, 0, len(pr.Labels))
+	for _, l := range pr.Labels {
+		labels = append(labels, l.Name)
+	}
+	return &Request{
+		Number:  pr.Number,
+		URL:     pr.HTMLURL,
+		Title:   pr.Title,
+		Body:    pr.Body,
+		Head:    pr.Head.Ref,
+		HeadSHA: pr.Head.SHA,
+		Base:    pr.Base.Ref,
+		Labels:  labels,
+	}
+}
+
+// FindOpen lists the open pull requests page by page, as Gitea can't filter
+// them by the head branch.
+func (g gitea) FindOpen(ctx context.Context, head, base string) (*Request, error) {
+	const limit = 50
+	for page := 1; ; page++ {
+		path := fmt.Sprintf("%s/pulls?state=open&limit=%d&page=%d", g.repo, limit, page)
+		prs := make([]giteaPR, 0, limit)
+		if err := g.client.Do(ctx, http.MethodGet, path, nil, &prs); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+		for _, pr := range prs {
+			if pr.Head.Ref == head && pr.Base.Ref == base {
+				return pr.request(), nil
+			}
+		}
+		if len(prs) < limit {
+			return nil, ErrNotFound
+		}
+	}
+}
+
+func (g gitea) Open(ctx context.Context, req NewRequest) (*Request, error) {
+	title := req.Title
+	if req.Draft {
+		title = "WIP: " + title
+	}
+	var pr giteaPR
+	err := g.client.Do(ctx, http.MethodPost, g.repo+"/pulls", map[string]string{
+		"title": title,
+		"body":  req.Body,
+		"head":  req.Head,
+		"base":  req.Base,
+	}, &pr)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	return pr.request(), nil
+}
+
+func (g gitea) Close(ctx context.Context, number int) error {
+	path := fmt.Sprintf("%s/pulls/%d", g.repo, number)
+	return g.client.Do(ctx, http.MethodPatch, path, //nolint:wrapcheck
+		map[string]string{"state": "closed"}, nil)
+}
+
+func (g gitea) Update(ctx context.Context, number int, update Update) (*Request, error) {
+	body := map[string]string{}
+	if update.Title != nil {
+		body["title"] = *update.Title
+	}
+	if update.Body != nil {
+		body["body"] = *update.Body
+	}
+	var pr giteaPR
+	path := fmt.Sprintf("%s/pulls/%d", g.repo, number)
+	if err := g.client.Do(ctx, http.MethodPatch, path, body, &pr); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	return pr.request(), nil
+}
+
+func (g gitea) AddLabels(ctx context.Context, number int, labels ...string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	path := fmt.Sprintf("%s/issues/%d/labels", g.repo, number)
+	return g.client.Do(ctx, http.MethodPost, path, //nolint:wrapcheck
+		map[string][]string{"labels": labels}, nil)
+}
+
+func (g gitea) Comment(ctx context.Context, number int, body string) error {
+	path := fmt.Sprintf("%s/issues/%d/comments", g.repo, number)
+	return g.client.Do(ctx, http.MethodPost, path, //nolint:wrapcheck
+		map[string]string{"body": body}, nil)
+}
+
+func (g gitea) Configure(ctx context.Context, req *Request, s Settings) error {
+	var errs []error
+	if len(s.Reviewers) > 0 || len(s.TeamReviewers) > 0 {
+		path := fmt.Sprintf("%s/pulls/%d/requested_reviewers", g.repo, req.Number)
+		errs = append(errs, g.client.Do(ctx, http.MethodPost, path, map[string][]string{
+			"reviewers":      s.Reviewers,
+			"team_reviewers": s.TeamReviewers,
+		}, nil))
+	}
+	issue := map[string]any{}
+	if len(s.Assignees) > 0 {
+		issue["assignees"] = s.Assignees
+	}
+	if s.Milestone != "" {
+		id, err := g.milestoneID(ctx, s.Milestone)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			issue["milestone"] = id
+		}
+	}
+	if len(issue) > 0 {
+		path := fmt.Sprintf("%s/issues/%d", g.repo, req.Number)
+		errs = append(errs, g.client.Do(ctx, http.MethodPatch, path, issue, nil))
+	}
+	if s.AutoMerge != "" {
+		path := fmt.Sprintf("%s/pulls/%d/merge", g.repo, req.Number)
+		errs = append(errs, g.client.Do(ctx, http.MethodPost, path, map[string]any{
+			"Do":                        string(s.AutoMerge),
+			"merge_when_checks_succeed": true,
+		}, nil))
+	}
+	return errors.Join(errs...)
+}
+
+func (g gitea) milestoneID(ctx context.Context, title string) (int, error) {
+	var milestones []struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	}
+	err := g.client.Do(ctx, http.MethodGet, g.repo+"/milestones?state=open&name="+
+		url.QueryEscape(title), nil, &milestones)
+	if err != nil {
+		return 0, err //nolint:wrapcheck
+	}
+	for _, m := range milestones {
+		if m.Title == title {
+			return m.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: no open milestone %q", ErrUnsupportedSetting, title)
+}
+
+func giteaError(body []byte) string {
+	var msg struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return ""
+	}
+	return msg.Message
+}
@@ -0,0 +1,118 @@
+package forge
+
+import (
+	"context"
+
+	"github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/github"
+)
+
+type gitHub struct {
+	client *github.Client
+	repo   string
+}
+
+func newGitHub(host, repo, url string) gitHub {
+	cl := github.NewClient()
+	switch {
+	case url != "":
+		cl.BaseURL = url
+	case host != "" && host != "github.com":
+		cl.BaseURL = baseURL("", host, "/api/v3")
+	}
+	return gitHub{client: cl, repo: repo}
+}
+
+func (g gitHub) FindOpen(ctx context.Context, head, base string) (*Request, error) {
+	prs, err := g.client.ListPullRequests(ctx, g.repo, github.ListOptions{
+		Head: head, Base: base,
+	})
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if len(prs) == 0 {
+		return nil, ErrNotFound
+	}
+	return fromGitHub(prs[0]), nil
+}
+
+func (g gitHub) Open(ctx context.Context, req NewRequest) (*Request, error) {
+	pr, err := g.client.CreatePullRequest(ctx, g.repo, github.NewPullRequest{
+		Title: req.Title,
+		Body:  req.Body,
+		Head:  req.Head,
+		Base:  req.Base,
+		Draft: req.Draft,
+	})
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	return fromGitHub(*pr), nil
+}
+
+func (g gitHub) Close(ctx context.Context, number int) error {
+	closed := "closed"
+	_, err := g.client.UpdatePullRequest(ctx, g.repo, number, github.PullRequestUpdate{
+		State: &closed,
+	})
+	return err //nolint:wrapcheck
+}
+
+func (g gitHub) Update(ctx context.Context, number int, update Update) (*Request, error) {
+	pr, err := g.client.UpdatePullRequest(ctx, g.repo, number, github.PullRequestUpdate{
+		Title: update.Title,
+		Body:  update.Body,
+	})
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	return fromGitHub(*pr), nil
+}
+
+func (g gitHub) AddLabels(ctx context.Context, number int, labels ...string) error {
+	return g.client.AddLabels(ctx, g.repo, number, labels...) //nolint:wrapcheck
+}
+
+func (g gitHub) Comment(ctx context.Context, number int, body string) error {
+	return g.client.CreateComment(ctx, g.repo, number, body) //nolint:wrapcheck
+}
+
+func (g gitHub) Configure(ctx context.Context, req *Request, s Settings) error {
+	var errs []error
+	if err := g.client.RequestReviewers(ctx, g.repo, req.Number,
+		s.Reviewers, s.TeamReviewers); err != nil {
+		errs = append(errs, err)
+	}
+	if err := g.client.AddAssignees(ctx, g.repo, req.Number, s.Assignees...); err != nil {
+		errs = append(errs, err)
+	}
+	if s.Milestone != "" {
+		if err := g.client.SetMilestone(ctx, g.repo, req.Number, s.Milestone); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if s.AutoMerge != "" {
+		if err := g.client.EnableAutoMerge(ctx, req.ID, string(s.AutoMerge)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func fromGitHub(pr github.PullRequest) *Request {
+	labels := make([]string, 0, len(pr.Labels))
+	for _, l := range pr.Labels {
+		labels = append(labels, l.Name)
+	}
+	return &Request{
+		ID:      pr.NodeID,
+		Number:  pr.Number,
+		URL:     pr.URL,
+		Title:   pr.Title,
+		Body:    pr.Body,
+		Head:    pr.Head.Ref,
+		HeadSHA: pr.Head.SHA,
+		Base:    pr.Base.Ref,
+		Labels:  labels,
+	}
+}
@@ -0,0 +1,229 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/rest"
+)
+
+// gitLab talks to the GitLab v4 API. The token is taken from GITLAB_TOKEN.
+type gitLab struct {
+	client  rest.Client
+	project string
+}
+
+func newGitLab(host, repo, apiURL string) gitLab {
+	header := http.Header{}
+	if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+		header.Set("PRIVATE-TOKEN", token)
+	}
+	return gitLab{
+		client: rest.Client{
+			BaseURL:      baseURL(apiURL, host, "/api/v4"),
+			Header:       header,
+			ErrorMessage: gitLabError,
+		},
+		project: "/projects/" + url.PathEscape(repo),
+	}
+}
+
+type gitLabMR struct {
+	IID          int      `json:"iid"`
+	WebURL       string   `json:"web_url"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	SourceBranch string   `json:"source_branch"`
+	TargetBranch string   `json:"target_branch"`
+	SHA          string   `json:"sha"`
+	Labels       []string `json:"labels"`
+}
+
+func (mr gitLabMR) request() *Request {
+	return &Request{
+		Number:  mr.IID,
+		URL:     mr.WebURL,
+		Title:   mr.Title,
+		Body:    mr.Description,
+		Head:    mr.SourceBranch,
+		HeadSHA: mr.SHA,
+		Base:    mr.TargetBranch,
+		Labels:  mr.Labels,
+	}
+}
+
+func (g gitLab) FindOpen(ctx context.Context, head, base string) (*Request, error) {
+	q := url.Values{}
+	q.Set("state", "opened")
+	q.Set("source_branch", head)
+	q.Set("target_branch", base)
+	mrs := make([]gitLabMR, 0)
+	err := g.client.Do(ctx, http.MethodGet, g.project+"/merge_requests?"+q.Encode(), nil, &mrs)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if len(mrs) == 0 {
+		return nil, ErrNotFound
+	}
+	return mrs[0].request(), nil
+}
+
+func (g gitLab) Open(ctx context.Context, req NewRequest) (*Request, error) {
+	title := req.Title
+	if req.Draft {
+		title = "Draft: " + title
+	}
+	var mr gitLabMR
+	err := g.client.Do(ctx, http.MethodPost, g.project+"/merge_requests", map[string]string{
+		"title":         title,
+		"description":   req.Body,
+		"source_branch": req.Head,
+		"target_branch": req.Base,
+	}, &mr)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	return mr.request(), nil
+}
+
+func (g gitLab) Close(ctx context.Context, number int) error {
+	return g.client.Do(ctx, http.MethodPut, g.mergeRequest(number), //nolint:wrapcheck
+		map[string]string{"state_event": "close"}, nil)
+}
+
+func (g gitLab) Update(ctx context.Context, number int, update Update) (*Request, error) {
+	body := map[string]string{}
+	if update.Title != nil {
+		body["title"] = *update.Title
+	}
+	if update.Body != nil {
+		body["description"] = *update.Body
+	}
+	var mr gitLabMR
+	if err := g.client.Do(ctx, http.MethodPut, g.mergeRequest(number), body, &mr); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	return mr.request(), nil
+}
+
+func (g gitLab) AddLabels(ctx context.Context, number int, labels ...string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	return g.client.Do(ctx, http.MethodPut, g.mergeRequest(number), //nolint:wrapcheck
+		map[string]string{"add_labels": strings.Join(labels, ",")}, nil)
+}
+
+func (g gitLab) Comment(ctx context.Context, number int, body string) error {
+	return g.client.Do(ctx, http.MethodPost, g.mergeRequest(number)+"/notes", //nolint:wrapcheck
+		map[string]string{"body": body}, nil)
+}
+
+func (g gitLab) Configure(ctx context.Context, req *Request, s Settings) error {
+	var errs []error
+	if len(s.TeamReviewers) > 0 {
+		errs = append(errs, fmt.Errorf("%w: GitLab has no team reviewers",
+			ErrUnsupportedSetting))
+	}
+	body := map[string]any{}
+	for key, users := range map[string][]string{
+		"reviewer_ids": s.Reviewers,
+		"assignee_ids": s.Assignees,
+	} {
+		if len(users) == 0 {
+			continue
+		}
+		ids, err := g.userIDs(ctx, users)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		body[key] = ids
+	}
+	if s.Milestone != "" {
+		id, err := g.milestoneID(ctx, s.Milestone)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			body["milestone_id"] = id
+		}
+	}
+	if len(body) > 0 {
+		err := g.client.Do(ctx, http.MethodPut, g.mergeRequest(req.Number), body, nil)
+		errs = append(errs, err)
+	}
+	if s.AutoMerge != "" {
+		errs = append(errs, g.autoMerge(ctx, req.Number, s.AutoMerge))
+	}
+	return errors.Join(errs...)
+}
+
+func (g gitLab) autoMerge(ctx context.Context, number int, method MergeMethod) error {
+	if method == Rebase {
+		return fmt.Errorf("%w: GitLab can't auto-merge with %q method",
+			ErrUnsupportedSetting, method)
+	}
+	return g.client.Do(ctx, http.MethodPut, g.mergeRequest(number)+"/merge", //nolint:wrapcheck
+		map[string]bool{
+			"merge_when_pipeline_succeeds": true,
+			"squash":                       method == Squash,
+		}, nil)
+}
+
+func (g gitLab) userIDs(ctx context.Context, usernames []string) ([]int, error) {
+	ids := make([]int, 0, len(usernames))
+	for _, username := range usernames {
+		var users []struct {
+			ID int `json:"id"`
+		}
+		err := g.client.Do(ctx, http.MethodGet,
+			"/users?username="+url.QueryEscape(username), nil, &users)
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("%w: no GitLab user %q", ErrUnsupportedSetting, username)
+		}
+		ids = append(ids, users[0].ID)
+	}
+	return ids, nil
+}
+
+func (g gitLab) milestoneID(ctx context.Context, title string) (int, error) {
+	var milestones []struct {
+		ID int `json:"id"`
+	}
+	err := g.client.Do(ctx, http.MethodGet, g.project+"/milestones?state=active&title="+
+		url.QueryEscape(title), nil, &milestones)
+	if err != nil {
+		return 0, err //nolint:wrapcheck
+	}
+	if len(milestones) == 0 {
+		return 0, fmt.Errorf("%w: no active milestone %q", ErrUnsupportedSetting, title)
+	}
+	return milestones[0].ID, nil
+}
+
+func (g gitLab) mergeRequest(number int) string {
+	return fmt.Sprintf("%s/merge_requests/%d", g.project, number)
+}
+
+func gitLabError(body []byte) string {
+	var msg struct {
+		Message any    `json:"message"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return ""
+	}
+	if msg.Message != nil {
+		return fmt.Sprint(msg.Message)
+	}
+	return msg.Error
+}
@@ -0,0 +1,256 @@
+package git
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/openshift-knative/deviate/pkg/diff3"
+	"github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/patch"
+)
+
+var (
+	// ErrPatchFailed when the patch can't be applied onto the worktree.
+	ErrPatchFailed = errors.New("patch failed")
+
+	errAmbiguousHash = errors.New("ambiguous or unknown object")
+)
+
+// ApplyPatch applies the patch file onto the worktree, similarly to git
+// apply --3way. Either all the changes from the patch are applied, or none.
+func (r Repository) ApplyPatch(patchFile string) error {
+	f, err := os.Open(patchFile)
+	if err != nil {
+		return errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	diffs, err := patch.Parse(f)
+	if err != nil {
+		return fmt.Errorf("%s - %w: %w", patchFile, ErrPatchFailed, err)
+	}
+	wt, err := r.Worktree()
+	if err != nil {
+		return errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	a := applier{repo: r, fs: wt.Filesystem, files: make(map[string]*pendingFile)}
+	errs := make([]error, 0)
+	for _, fd := range diffs {
+		if err = a.apply(fd); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s - %w: %w", patchFile, ErrPatchFailed,
+			errors.Join(errs...))
+	}
+	return a.write()
+}
+
+// pendingFile is a file changed by the patch, but not yet written. The nil
+// content represents a deleted file.
+type pendingFile struct {
+	content *string
+	mode    fs.FileMode
+}
+
+type applier struct {
+	repo  Repository
+	fs    billy.Filesystem
+	files map[string]*pendingFile
+	order []string
+}
+
+func (a *applier) apply(fd *patch.FileDiff) error {
+	if fd.IsBinary {
+		return fmt.Errorf("%w: %s: binary patches are not supported",
+			patch.ErrPatchDoesNotApply, fd.Name())
+	}
+	current, mode, err := a.read(fd)
+	if err != nil {
+		return err
+	}
+	result, err := patch.Apply(current, fd, patch.Options{Fuzz: patch.DefaultFuzz})
+	if err != nil {
+		var merr error
+		if result, merr = a.threeWay(current, fd); merr != nil {
+			return errors.Join(err, merr)
+		}
+	}
+	if fd.NewMode != 0 {
+		mode = toFileMode(fd.NewMode)
+	}
+	if fd.IsRename {
+		a.set(fd.OldName, &pendingFile{})
+	}
+	if fd.IsDelete {
+		a.set(fd.OldName, &pendingFile{})
+		return nil
+	}
+	a.set(fd.Name(), &pendingFile{content: &result, mode: mode})
+	return nil
+}
+
+func (a *applier) read(fd *patch.FileDiff) (string, fs.FileMode, error) {
+	const defaultMode = 0o644
+	if fd.IsNew {
+		if _, ok, err := a.lookup(fd.NewName); err != nil || ok {
+			return "", 0, errors.Join(err, fmt.Errorf(
+				"%w: %s: already exists in working directory",
+				patch.ErrPatchDoesNotApply, fd.NewName))
+		}
+		return "", defaultMode, nil
+	}
+	pf, ok, err := a.lookup(fd.OldName)
+	if err != nil {
+		return "", 0, err
+	}
+	if !ok {
+		return "", 0, fmt.Errorf("%w: %s: no such file in working directory",
+			patch.ErrPatchDoesNotApply, fd.OldName)
+	}
+	return *pf.content, pf.mode, nil
+}
+
+func (a *applier) lookup(name string) (*pendingFile, bool, error) {
+	if pf, ok := a.files[name]; ok {
+		return pf, pf.content != nil, nil
+	}
+	fi, err := a.fs.Lstat(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	bytes, err := util.ReadFile(a.fs, name)
+	if err != nil {
+		return nil, false, errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	content := string(bytes)
+	return &pendingFile{content: &content, mode: fi.Mode().Perm()}, true, nil
+}
+
+func (a *applier) set(name string, pf *pendingFile) {
+	if _, ok := a.files[name]; !ok {
+		a.order = append(a.order, name)
+	}
+	a.files[name] = pf
+}
+
+// threeWay applies the patch onto the original blob, as recorded in the index
+// line of the patch, and merges the result with the current content.
+func (a *applier) threeWay(current string, fd *patch.FileDiff) (string, error) {
+	if fd.OldHash == "" || strings.Trim(fd.OldHash, "0") == "" {
+		return "", fmt.Errorf("%w: %s: no preimage blob to fall back on "+
+			"3-way merge", patch.ErrPatchDoesNotApply, fd.Name())
+	}
+	base, err := a.repo.blobContent(fd.OldHash)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: 3-way merge: %w",
+			patch.ErrPatchDoesNotApply, fd.Name(), err)
+	}
+	theirs, err := patch.Apply(base, fd, patch.Options{})
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: 3-way merge: %w",
+			patch.ErrPatchDoesNotApply, fd.Name(), err)
+	}
+	merged := diff3.Merge(base, current, theirs, diff3.Labels{
+		Ours: "ours", Theirs: "theirs",
+	})
+	if !merged.Clean() {
+		errs := make([]error, 0, len(merged.Conflicts))
+		for _, c := range merged.Conflicts {
+			errs = append(errs, fmt.Errorf("%w: %s:%d: 3-way merge conflict",
+				patch.ErrPatchDoesNotApply, fd.Name(), c.Line))
+		}
+		return "", errors.Join(errs...)
+	}
+	return merged.Content, nil
+}
+
+func (a *applier) write() error {
+	for _, name := range a.order {
+		pf := a.files[name]
+		if _, err := a.fs.Lstat(name); err == nil {
+			if err = a.fs.Remove(name); err != nil {
+				return errors.Wrap(err, ErrLocalOperationFailed)
+			}
+		}
+		if pf.content == nil {
+			continue
+		}
+		err := util.WriteFile(a.fs, name, []byte(*pf.content), pf.mode)
+		if err != nil {
+			return errors.Wrap(err, ErrLocalOperationFailed)
+		}
+	}
+	return nil
+}
+
+func (r Repository) blobContent(prefix string) (string, error) {
+	hash, err := r.expandHash(prefix)
+	if err != nil {
+		return "", err
+	}
+	blob, err := r.BlobObject(hash)
+	if err != nil {
+		return "", errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return "", errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	bytes, err := io.ReadAll(reader)
+	return string(bytes), errors.Wrap(err, ErrLocalOperationFailed)
+}
+
+func (r Repository) expandHash(prefix string) (plumbing.Hash, error) {
+	if len(prefix) == len(plumbing.ZeroHash)*2 {
+		return plumbing.NewHash(prefix), nil
+	}
+	hexb, err := hex.DecodeString(prefix[:len(prefix)&^1])
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("%w: %s", errAmbiguousHash, prefix)
+	}
+	type prefixer interface {
+		HashesWithPrefix(prefix []byte) ([]plumbing.Hash, error)
+	}
+	var candidates []plumbing.Hash
+	if p, ok := r.Storer.(prefixer); ok {
+		candidates, err = p.HashesWithPrefix(hexb)
+		if err != nil {
+			return plumbing.ZeroHash, errors.Wrap(err, ErrLocalOperationFailed)
+		}
+	}
+	found := make([]plumbing.Hash, 0, 1)
+	for _, h := range candidates {
+		if strings.HasPrefix(h.String(), prefix) {
+			found = append(found, h)
+		}
+	}
+	if len(found) != 1 {
+		return plumbing.ZeroHash, fmt.Errorf("%w: %s", errAmbiguousHash, prefix)
+	}
+	return found[0], nil
+}
+
+func toFileMode(mode uint32) fs.FileMode {
+	if fm, err := filemode.FileMode(mode).ToOSFileMode(); err == nil {
+		return fm.Perm()
+	}
+	const defaultMode = 0o644
+	return defaultMode
+}
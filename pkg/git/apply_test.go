@@ -0,0 +1,100 @@
+package git_test
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	gitv5 "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/openshift-knative/deviate/pkg/config"
+	"github.com/openshift-knative/deviate/pkg/git"
+	"github.com/openshift-knative/deviate/pkg/patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_ApplyPatch(t *testing.T) {
+	const original = "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\n"
+	projectPath := t.TempDir()
+	gr, err := gitv5.PlainInit(projectPath, false)
+	require.NoError(t, err)
+	writeFile(t, projectPath, "a.txt", original)
+	wt, err := gr.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Add("a.txt")
+	require.NoError(t, err)
+	hash, err := wt.Commit("init", &gitv5.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.org"},
+	})
+	require.NoError(t, err)
+	commit, err := gr.CommitObject(hash)
+	require.NoError(t, err)
+	blob, err := commit.File("a.txt")
+	require.NoError(t, err)
+	repo := &git.Repository{
+		Context:    t.Context(),
+		Project:    config.Project{Path: projectPath},
+		Repository: gr,
+	}
+	patchFile := path.Join(t.TempDir(), "change.patch")
+	writeFile(t, path.Dir(patchFile), path.Base(patchFile), fmt.Sprintf(`diff --git a/a.txt b/a.txt
+index %s..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,9 +1,9 @@
+ one
+-two
++TWO
+ three
+ four
+ five
+ six
+ seven
+-eight
++EIGHT
+ nine
+diff --git a/b.txt b/b.txt
+new file mode 100644
+--- /dev/null
++++ b/b.txt
+@@ -0,0 +1 @@
++b
+`, blob.Hash.String()[:7]))
+
+	t.Run("3-way", func(t *testing.T) {
+		writeFile(t, projectPath, "a.txt",
+			"one\ntwo\nthree\nfour\nFIVE\nsix\nseven\neight\nnine\n")
+		require.NoError(t, repo.ApplyPatch(patchFile))
+		assert.Equal(t, "one\nTWO\nthree\nfour\nFIVE\nsix\nseven\nEIGHT\nnine\n",
+			readFile(t, projectPath, "a.txt"))
+		assert.Equal(t, "b\n", readFile(t, projectPath, "b.txt"))
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		require.NoError(t, os.Remove(path.Join(projectPath, "b.txt")))
+		const changed = "one\n2\nthree\nfour\nfive\nsix\nseven\neight\nnine\n"
+		writeFile(t, projectPath, "a.txt", changed)
+		err := repo.ApplyPatch(patchFile)
+		require.ErrorIs(t, err, git.ErrPatchFailed)
+		require.ErrorIs(t, err, patch.ErrPatchDoesNotApply)
+		assert.Contains(t, err.Error(), "a.txt:1: hunk #1")
+		assert.Equal(t, changed, readFile(t, projectPath, "a.txt"))
+		assert.NoFileExists(t, path.Join(projectPath, "b.txt"))
+	})
+}
+
+func writeFile(tb testing.TB, dir, name, content string) {
+	tb.Helper()
+	pth := path.Join(dir, name)
+	require.NoError(tb, os.MkdirAll(path.Dir(pth), 0o750))
+	require.NoError(tb, os.WriteFile(pth, []byte(content), 0o600))
+}
+
+func readFile(tb testing.TB, dir, name string) string {
+	tb.Helper()
+	bytes, err := os.ReadFile(path.Join(dir, name))
+	require.NoError(tb, err)
+	return string(bytes)
+}
@@ -1,34 +1,87 @@
 package git
 
 import (
-	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
-	"github.com/mitchellh/go-homedir"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/openshift-knative/deviate/pkg/config"
 	"github.com/openshift-knative/deviate/pkg/config/git"
 	"github.com/openshift-knative/deviate/pkg/errors"
 	"github.com/openshift-knative/deviate/pkg/url"
-	sshagent "github.com/xanzy/ssh-agent"
 )
 
-func authentication(remote git.Remote) (ssh.AuthMethod, error) { //nolint:ireturn
+// ErrNoToken when the configured token variable is empty.
+var ErrNoToken = errors.New("no token")
+
+func (r Repository) authentication(remote git.Remote) (transport.AuthMethod, error) { //nolint:ireturn
+	return r.remoteAuthentication(remote, r.Auth.Remote(remote.Name))
+}
+
+func (r Repository) remoteAuthentication(remote git.Remote, cfg config.RemoteAuth) (transport.AuthMethod, error) { //nolint:ireturn
 	if url.IsHTTP(remote.URL) {
-		// The auth isn't required for getting HTTP remote from GH
+		return r.httpAuthentication(remote, cfg.HTTP)
+	}
+	if isLocal(remote.URL) {
 		return nil, nil //nolint:nilnil
 	}
-	if sshagent.Available() {
-		user := ""
-		if addr, err := ParseAddress(remote.URL); err == nil {
-			user = addr.User
+	return sshAuthentication(remote, cfg.SSH)
+}
+
+// httpAuthentication returns the basic auth with the token of the remote,
+// or no auth for anonymous access, if there's no token.
+func (r Repository) httpAuthentication(remote git.Remote, cfg config.HTTPAuth) (transport.AuthMethod, error) { //nolint:ireturn
+	creds, err := r.findCredentials(remote, cfg)
+	if err != nil || creds == nil {
+		return nil, err
+	}
+	if creds.username == "" {
+		creds.username = cfg.Username
+	}
+	return &http.BasicAuth{
+		Username: creds.username,
+		Password: creds.password,
+	}, nil
+}
+
+type credentials struct {
+	username string
+	password string
+}
+
+func (r Repository) findCredentials(remote git.Remote, cfg config.HTTPAuth) (*credentials, error) {
+	if cfg.TokenEnv != "" {
+		token := os.Getenv(cfg.TokenEnv)
+		if token == "" {
+			return nil, fmt.Errorf("%w: the %s variable, configured for the %s "+
+				"remote, is empty", ErrNoToken, cfg.TokenEnv, remote.Name)
 		}
-		auth, err := ssh.NewSSHAgentAuth(user)
-		if err != nil {
-			return nil, errors.Wrap(err, ErrRemoteOperationFailed)
+		return &credentials{password: token}, nil
+	}
+	if token := os.Getenv(tokenVariable(remote)); token != "" {
+		return &credentials{password: token}, nil
+	}
+	if cfg.CredentialHelper {
+		if creds := r.credentialHelper(remote.URL); creds != nil {
+			return creds, nil
 		}
-		return auth, nil
 	}
-	idRsa, err := homedir.Expand("~/.ssh/id_rsa")
-	if err != nil {
-		return nil, errors.Wrap(err, ErrRemoteOperationFailed)
+	if cfg.Netrc {
+		return netrc(remote.URL)
 	}
-	auth, err := ssh.NewPublicKeysFromFile("git", idRsa, "")
-	return auth, errors.Wrap(err, ErrRemoteOperationFailed)
+	return nil, nil //nolint:nilnil
+}
+
+// tokenVariable is the name of the variable holding the remote token, like
+// DEVIATE_DOWNSTREAM_TOKEN.
+func tokenVariable(remote git.Remote) string {
+	name := strings.ToUpper(strings.ReplaceAll(remote.Name, "-", "_"))
+	return "DEVIATE_" + name + "_TOKEN"
+}
+
+func isLocal(remoteURL string) bool {
+	return strings.HasPrefix(remoteURL, "file://") || filepath.IsAbs(remoteURL)
 }
@@ -0,0 +1,119 @@
+package git_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	gitv5 "github.com/go-git/go-git/v5"
+	"github.com/openshift-knative/deviate/pkg/config"
+	configgit "github.com/openshift-knative/deviate/pkg/config/git"
+	"github.com/openshift-knative/deviate/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_HTTPAuthentication(t *testing.T) {
+	tcs := []struct {
+		name    string
+		auth    config.HTTPAuth
+		env     map[string]string
+		netrc   string
+		helper  string
+		user    string
+		pass    string
+		wantErr error
+	}{{
+		name: "anonymous",
+	}, {
+		name: "configured variable",
+		auth: config.HTTPAuth{Username: "bot", TokenEnv: "BOT_TOKEN"},
+		env:  map[string]string{"BOT_TOKEN": "s3cr3t"},
+		user: "bot",
+		pass: "s3cr3t",
+	}, {
+		name:    "empty configured variable",
+		auth:    config.HTTPAuth{TokenEnv: "BOT_TOKEN"},
+		wantErr: git.ErrNoToken,
+	}, {
+		name: "remote variable",
+		auth: config.HTTPAuth{Username: "x-access-token"},
+		env:  map[string]string{"DEVIATE_DOWNSTREAM_TOKEN": "t0k3n"},
+		user: "x-access-token",
+		pass: "t0k3n",
+	}, {
+		name:  "netrc",
+		auth:  config.HTTPAuth{Username: "x-access-token", Netrc: true},
+		netrc: "machine example.org login other password nope\nmachine 127.0.0.1 login alice password pa55\n",
+		user:  "alice",
+		pass:  "pa55",
+	}, {
+		name:   "project credential helper",
+		auth:   config.HTTPAuth{Username: "x-access-token", CredentialHelper: true, Netrc: true},
+		netrc:  "default password nope",
+		helper: "!f() { echo username=alice; echo password=pa55; }; f",
+		user:   "alice",
+		pass:   "pa55",
+	}, {
+		name:   "credential helper not enabled",
+		auth:   config.HTTPAuth{Username: "x-access-token", Netrc: true},
+		helper: "!f() { echo username=alice; echo password=pa55; }; f",
+	}, {
+		name:  "netrc default",
+		auth:  config.HTTPAuth{Username: "x-access-token", Netrc: true},
+		netrc: "default password pa55",
+		user:  "x-access-token",
+		pass:  "pa55",
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("BOT_TOKEN", "")
+			t.Setenv("DEVIATE_DOWNSTREAM_TOKEN", "")
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			netrcPath := path.Join(t.TempDir(), "netrc")
+			require.NoError(t, os.WriteFile(netrcPath, []byte(tc.netrc), 0o600))
+			t.Setenv("NETRC", netrcPath)
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+			projectPath := t.TempDir()
+			if tc.helper != "" {
+				gr, err := gitv5.PlainInit(projectPath, false)
+				require.NoError(t, err)
+				cfg, err := gr.Config()
+				require.NoError(t, err)
+				cfg.Raw.Section("credential").SetOption("helper", tc.helper)
+				require.NoError(t, gr.SetConfig(cfg))
+			}
+			var user, pass string
+			var authorized bool
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				user, pass, authorized = r.BasicAuth()
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			defer srv.Close()
+			repo := &git.Repository{
+				Context: t.Context(),
+				Project: config.Project{Path: projectPath},
+				Auth:    config.Auth{Downstream: config.RemoteAuth{HTTP: tc.auth}},
+			}
+
+			_, err := repo.ListRemote(configgit.Remote{
+				Name: "downstream",
+				URL:  srv.URL + "/acme/fork.git",
+			})
+
+			require.Error(t, err)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			assert.Equal(t, tc.pass != "", authorized)
+			assert.Equal(t, tc.user, user)
+			assert.Equal(t, tc.pass, pass)
+		})
+	}
+}
@@ -28,6 +28,22 @@ func (r Repository) Checkout(remote git.Remote, branch string) git.Checkout { //
 	}
 }
 
+// SwitchTo checks out the local branch, or the commit, if the reference is
+// a detached head. The changes of the worktree are discarded.
+func (r Repository) SwitchTo(ref *plumbing.Reference) error {
+	wt, err := r.Worktree()
+	if err != nil {
+		return errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	opts := &gitv5.CheckoutOptions{Force: true}
+	if ref.Name().IsBranch() {
+		opts.Branch = ref.Name()
+	} else {
+		opts.Hash = ref.Hash()
+	}
+	return errors.Wrap(wt.Checkout(opts), ErrLocalOperationFailed)
+}
+
 type onGoingCheckout struct {
 	remote git.Remote
 	branch string
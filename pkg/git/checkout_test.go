@@ -73,3 +73,40 @@ func TestCheckout_OntoWorkspace(t *testing.T) {
 	slices.Sort(got)
 	assert.Equal(t, []string{"build/mage.go", "cmd/ght/main.go"}, got)
 }
+
+func TestRepository_SwitchTo(t *testing.T) {
+	projectPath := t.TempDir()
+	gr, err := gitv5.PlainInit(projectPath, false)
+	require.NoError(t, err)
+	commitFiles(t, gr, projectPath, map[string]string{"a.txt": "a\n"})
+	head, err := gr.Head()
+	require.NoError(t, err)
+	wt, err := gr.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, wt.Checkout(&gitv5.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName("feature"),
+		Create: true,
+	}))
+	commitFiles(t, gr, projectPath, map[string]string{"a.txt": "b\n"})
+	writeFile(t, projectPath, "a.txt", "dirty\n")
+	repo := &git.Repository{
+		Context:    t.Context(),
+		Project:    config.Project{Path: projectPath},
+		Repository: gr,
+	}
+
+	require.NoError(t, repo.SwitchTo(head))
+
+	got, err := gr.Head()
+	require.NoError(t, err)
+	assert.Equal(t, head.Name(), got.Name())
+	assert.Equal(t, "a\n", readFile(t, projectPath, "a.txt"))
+
+	detached := plumbing.NewHashReference(plumbing.HEAD, head.Hash())
+	require.NoError(t, repo.SwitchTo(detached))
+
+	got, err = gr.Head()
+	require.NoError(t, err)
+	assert.Equal(t, plumbing.HEAD, got.Name())
+	assert.Equal(t, head.Hash(), got.Hash())
+}
@@ -0,0 +1,50 @@
+package git
+
+import (
+	"strings"
+
+	gitv5 "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/openshift-knative/deviate/pkg/config/git"
+	"github.com/openshift-knative/deviate/pkg/errors"
+)
+
+// Clone clones the repository into the directory, so it can be worked on
+// independently. The clone shares the objects with the repository, has the
+// same local branches, and has nothing checked out.
+func (r Repository) Clone(dir string) (git.Repository, error) { //nolint:ireturn
+	const origin = "origin"
+	gr, err := gitv5.PlainCloneContext(r.Context, dir, false, &gitv5.CloneOptions{
+		URL:        r.Path,
+		RemoteName: origin,
+		NoCheckout: true,
+		Shared:     true,
+		Tags:       gitv5.NoTags,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	refs, err := gr.References()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+		if !name.IsRemote() || ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		branch, ok := strings.CutPrefix(name.Short(), origin+"/")
+		if !ok {
+			return nil
+		}
+		return gr.Storer.SetReference(plumbing.NewHashReference(
+			plumbing.NewBranchReferenceName(branch), ref.Hash()))
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	clone := r
+	clone.Repository = gr
+	clone.Path = dir
+	return &clone, nil
+}
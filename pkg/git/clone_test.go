@@ -0,0 +1,55 @@
+package git_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	gitv5 "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/openshift-knative/deviate/pkg/config"
+	"github.com/openshift-knative/deviate/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_Clone(t *testing.T) {
+	projectPath := t.TempDir()
+	gr, err := gitv5.PlainInit(projectPath, false)
+	require.NoError(t, err)
+	commitFiles(t, gr, projectPath, map[string]string{"a.txt": "a\n"})
+	head, err := gr.Head()
+	require.NoError(t, err)
+	other := plumbing.NewBranchReferenceName("other")
+	require.NoError(t, gr.Storer.SetReference(plumbing.NewHashReference(other, head.Hash())))
+	repo := &git.Repository{
+		Context:    t.Context(),
+		Project:    config.Project{Path: projectPath},
+		Repository: gr,
+		Commits: config.Commits{
+			Author: config.Identity{Name: "Deviate Bot", Email: "bot@example.org"},
+		},
+	}
+	dir := path.Join(t.TempDir(), "clone")
+
+	clone, err := repo.Clone(dir)
+
+	require.NoError(t, err)
+	_, err = os.Stat(path.Join(dir, "a.txt"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+	for _, branch := range []plumbing.ReferenceName{head.Name(), other} {
+		hash, rerr := clone.ResolveRevision(plumbing.Revision(branch))
+		require.NoError(t, rerr)
+		assert.Equal(t, head.Hash(), *hash)
+	}
+
+	require.NoError(t, clone.SwitchTo(head))
+	writeFile(t, dir, "b.txt", "b\n")
+	_, err = clone.CommitChanges("change")
+	require.NoError(t, err)
+	got, err := gr.Head()
+	require.NoError(t, err)
+	assert.Equal(t, head.Hash(), got.Hash())
+	_, err = os.Stat(path.Join(projectPath, "b.txt"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
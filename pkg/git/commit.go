@@ -27,10 +27,14 @@ func (r Repository) CommitChanges(message string) (*object.Commit, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, ErrLocalOperationFailed)
 	}
-	var hash plumbing.Hash
-	hash, err = wt.Commit(message, &gitv5.CommitOptions{
+	opts := &gitv5.CommitOptions{
 		All: true,
-	})
+	}
+	if message, err = r.commitOptions(message, opts); err != nil {
+		return nil, err
+	}
+	var hash plumbing.Hash
+	hash, err = wt.Commit(message, opts)
 	if err != nil {
 		return nil, errors.Wrap(err, ErrLocalOperationFailed)
 	}
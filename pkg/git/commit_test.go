@@ -0,0 +1,172 @@
+package git_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	gitv5 "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/openshift-knative/deviate/pkg/config"
+	"github.com/openshift-knative/deviate/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestRepository_CommitChanges(t *testing.T) {
+	bot := config.Identity{Name: "Deviate Bot", Email: "bot@example.org"}
+	tcs := []struct {
+		name    string
+		commits func(tb testing.TB, dir string) config.Commits
+		verify  func(tb testing.TB, dir string, c *object.Commit)
+	}{{
+		name: "git config identity",
+		commits: func(testing.TB, string) config.Commits {
+			return config.Commits{}
+		},
+		verify: func(tb testing.TB, _ string, c *object.Commit) {
+			assert.Equal(tb, "test", c.Author.Name)
+			assert.Equal(tb, "Apply", c.Message)
+			assert.Empty(tb, c.PGPSignature)
+		},
+	}, {
+		name: "configured identity with sign-off",
+		commits: func(testing.TB, string) config.Commits {
+			return config.Commits{Author: bot, SignOff: true}
+		},
+		verify: func(tb testing.TB, _ string, c *object.Commit) {
+			assert.Equal(tb, bot.Name, c.Author.Name)
+			assert.Equal(tb, bot.Email, c.Committer.Email)
+			assert.Equal(tb, "Apply\n\nSigned-off-by: Deviate Bot <bot@example.org>",
+				c.Message)
+		},
+	}, {
+		name: "gpg signature",
+		commits: func(tb testing.TB, dir string) config.Commits {
+			tb.Helper()
+			tb.Setenv("SIGNING_PASSPHRASE", "s3cr3t")
+			return config.Commits{Signing: config.Signing{
+				Format:        "gpg",
+				Key:           writeGPGKey(tb, dir, "s3cr3t"),
+				PassphraseEnv: "SIGNING_PASSPHRASE",
+			}}
+		},
+		verify: func(tb testing.TB, dir string, c *object.Commit) {
+			tb.Helper()
+			public, err := os.ReadFile(path.Join(dir, "public.asc"))
+			require.NoError(tb, err)
+			_, err = c.Verify(string(public))
+			require.NoError(tb, err)
+		},
+	}, {
+		name: "ssh signature",
+		commits: func(tb testing.TB, dir string) config.Commits {
+			tb.Helper()
+			return config.Commits{Author: bot, Signing: config.Signing{
+				Format: "ssh",
+				Key:    writeSSHSigningKey(tb, dir, bot.Email),
+			}}
+		},
+		verify: func(tb testing.TB, dir string, c *object.Commit) {
+			tb.Helper()
+			assert.True(tb, strings.HasPrefix(c.PGPSignature,
+				"-----BEGIN SSH SIGNATURE-----\n"), c.PGPSignature)
+			verifySSHSignature(tb, dir, bot.Email, c)
+		},
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			keysPath := t.TempDir()
+			projectPath := t.TempDir()
+			gr, err := gitv5.PlainInit(projectPath, false)
+			require.NoError(t, err)
+			cfg, err := gr.Config()
+			require.NoError(t, err)
+			cfg.User.Name = "test"
+			cfg.User.Email = "test@example.org"
+			require.NoError(t, gr.SetConfig(cfg))
+			repo := &git.Repository{
+				Context:    t.Context(),
+				Project:    config.Project{Path: projectPath},
+				Repository: gr,
+				Commits:    tc.commits(t, keysPath),
+			}
+			writeFile(t, projectPath, "a.txt", "a\n")
+
+			c, err := repo.CommitChanges("Apply")
+
+			require.NoError(t, err)
+			tc.verify(t, keysPath, c)
+		})
+	}
+}
+
+func writeGPGKey(tb testing.TB, dir, passphrase string) string {
+	tb.Helper()
+	entity, err := openpgp.NewEntity("Deviate Bot", "", "bot@example.org", nil)
+	require.NoError(tb, err)
+	var public bytes.Buffer
+	w, err := armor.Encode(&public, openpgp.PublicKeyType, nil)
+	require.NoError(tb, err)
+	require.NoError(tb, entity.Serialize(w))
+	require.NoError(tb, w.Close())
+	require.NoError(tb, os.WriteFile(path.Join(dir, "public.asc"), public.Bytes(), 0o600))
+
+	require.NoError(tb, entity.EncryptPrivateKeys([]byte(passphrase), nil))
+	var private bytes.Buffer
+	w, err = armor.Encode(&private, openpgp.PrivateKeyType, nil)
+	require.NoError(tb, err)
+	require.NoError(tb, entity.SerializePrivateWithoutSigning(w, nil))
+	require.NoError(tb, w.Close())
+	key := path.Join(dir, "private.asc")
+	require.NoError(tb, os.WriteFile(key, private.Bytes(), 0o600))
+	return key
+}
+
+func writeSSHSigningKey(tb testing.TB, dir, principal string) string {
+	tb.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(tb, err)
+	block, err := gossh.MarshalPrivateKey(priv, "")
+	require.NoError(tb, err)
+	key := path.Join(dir, "id_ed25519")
+	require.NoError(tb, os.WriteFile(key, pem.EncodeToMemory(block), 0o600))
+	sshPub, err := gossh.NewPublicKey(pub)
+	require.NoError(tb, err)
+	signers := principal + " " + string(gossh.MarshalAuthorizedKey(sshPub))
+	require.NoError(tb, os.WriteFile(path.Join(dir, "allowed_signers"),
+		[]byte(signers), 0o600))
+	return key
+}
+
+// verifySSHSignature verifies the signature with ssh-keygen, if available.
+func verifySSHSignature(tb testing.TB, dir, principal string, c *object.Commit) {
+	tb.Helper()
+	keygen, err := exec.LookPath("ssh-keygen")
+	if err != nil {
+		tb.Log("ssh-keygen isn't available, skipping the verification")
+		return
+	}
+	sig := path.Join(dir, "commit.sig")
+	require.NoError(tb, os.WriteFile(sig, []byte(c.PGPSignature), 0o600))
+	encoded := &plumbing.MemoryObject{}
+	require.NoError(tb, c.EncodeWithoutSignature(encoded))
+	reader, err := encoded.Reader()
+	require.NoError(tb, err)
+	cmd := exec.Command(keygen, "-Y", "verify", //nolint:gosec
+		"-f", path.Join(dir, "allowed_signers"),
+		"-I", principal, "-n", "git", "-s", sig)
+	cmd.Stdin = reader
+	out, err := cmd.CombinedOutput()
+	require.NoError(tb, err, string(out))
+}
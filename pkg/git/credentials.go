@@ -0,0 +1,131 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/sh"
+)
+
+// credentialHelper asks the git credential helpers for the credentials of
+// the remote, within the project, so the helpers configured for the project
+// are used. Nil is returned, if git isn't available, or no helper knows the
+// credentials.
+func (r Repository) credentialHelper(remoteURL string) *credentials {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return nil
+	}
+	var input strings.Builder
+	input.WriteString("protocol=" + u.Scheme + "\n")
+	input.WriteString("host=" + u.Host + "\n")
+	input.WriteString("path=" + strings.TrimPrefix(u.Path, "/") + "\n\n")
+
+	const timeout = 10 * time.Second
+	ctx, cancel := context.WithTimeout(r.Context, timeout)
+	defer cancel()
+	out, err := sh.Output(ctx, sh.Options{
+		Dir:   r.Path,
+		Env:   map[string]string{"GIT_TERMINAL_PROMPT": "0"},
+		Stdin: strings.NewReader(input.String()),
+	}, "git", "credential", "fill")
+	if err != nil {
+		return nil
+	}
+	creds := credentials{}
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		key, value, _ := strings.Cut(sc.Text(), "=")
+		switch key {
+		case "username":
+			creds.username = value
+		case "password":
+			creds.password = value
+		}
+	}
+	if creds.password == "" {
+		return nil
+	}
+	return &creds
+}
+
+// netrc looks up the credentials of the remote host in the netrc file, given
+// by the NETRC variable, or ~/.netrc by default.
+func netrc(remoteURL string) (*credentials, error) {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrRemoteOperationFailed)
+	}
+	path := os.Getenv("NETRC")
+	if path == "" {
+		if path, err = homedir.Expand("~/.netrc"); err != nil {
+			return nil, errors.Wrap(err, ErrRemoteOperationFailed)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil //nolint:nilnil
+		}
+		return nil, errors.Wrap(err, ErrRemoteOperationFailed)
+	}
+	return parseNetrc(string(data), u.Hostname()), nil
+}
+
+// parseNetrc returns the credentials of the machine, falling back to the
+// default entry.
+func parseNetrc(data, machine string) *credentials {
+	var found, fallback *credentials
+	var current *credentials
+	fields := strings.Fields(data)
+	for i := 0; i < len(fields); i++ {
+		next := func() string {
+			if i+1 < len(fields) {
+				i++
+				return fields[i]
+			}
+			return ""
+		}
+		switch fields[i] {
+		case "machine":
+			current = nil
+			if next() == machine && found == nil {
+				found = &credentials{}
+				current = found
+			}
+		case "default":
+			current = nil
+			if fallback == nil {
+				fallback = &credentials{}
+				current = fallback
+			}
+		case "login":
+			if login := next(); current != nil {
+				current.username = login
+			}
+		case "password":
+			if password := next(); current != nil {
+				current.password = password
+			}
+		case "macdef":
+			// Macros run until an empty line, and aren't supported.
+			return firstWithPassword(found, fallback)
+		}
+	}
+	return firstWithPassword(found, fallback)
+}
+
+func firstWithPassword(candidates ...*credentials) *credentials {
+	for _, c := range candidates {
+		if c != nil && c.password != "" {
+			return c
+		}
+	}
+	return nil
+}
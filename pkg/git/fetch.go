@@ -10,7 +10,7 @@ func (r Repository) Fetch(remote git.Remote) error {
 	if err := r.ensureRemote(remote); err != nil {
 		return err
 	}
-	auth, err := authentication(remote)
+	auth, err := r.authentication(remote)
 	if err != nil {
 		return err
 	}
@@ -18,7 +18,7 @@ func (r Repository) Fetch(remote git.Remote) error {
 		RemoteName: remote.Name,
 		Auth:       auth,
 	}); !errors.Is(err, gitv5.NoErrAlreadyUpToDate) {
-		return errors.Wrap(err, ErrRemoteOperationFailed)
+		return remoteError(remote, err)
 	}
 
 	return nil
@@ -0,0 +1,25 @@
+package git
+
+import (
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/openshift-knative/deviate/pkg/errors"
+)
+
+// Changelog returns the commits reachable from the until revision, but not
+// from the since revision, newest first, up to the given limit.
+func (r Repository) Changelog(since, until string, limit int) ([]*object.Commit, error) {
+	commits := make([]*object.Commit, 0, 2) //nolint:mnd
+	for _, rev := range []string{since, until} {
+		hash, err := r.ResolveRevision(plumbing.Revision(rev))
+		if err != nil {
+			return nil, errors.Wrap(err, ErrLocalOperationFailed)
+		}
+		c, err := r.CommitObject(*hash)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrLocalOperationFailed)
+		}
+		commits = append(commits, c)
+	}
+	return r.commitsBetween(commits[0], commits[1], limit)
+}
@@ -1,56 +1,543 @@
 package git
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
 
 	gitv5 "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/openshift-knative/deviate/pkg/config/git"
+	"github.com/openshift-knative/deviate/pkg/diff3"
 	"github.com/openshift-knative/deviate/pkg/errors"
-	pkgfiles "github.com/openshift-knative/deviate/pkg/files"
-	"github.com/openshift-knative/deviate/pkg/sh"
 )
 
-func (r Repository) Merge(remote *git.Remote, branch string) error {
-	var (
-		err    error
-		before *plumbing.Reference
-		after  *plumbing.Reference
-	)
+var (
+	// ErrMergeConflicts when the merge can't be performed automatically.
+	ErrMergeConflicts = errors.New("merge conflicts")
+
+	// ErrDirtyWorktree when the worktree has uncommitted changes.
+	ErrDirtyWorktree = errors.New("uncommitted changes in the worktree")
+)
+
+// MergeConflicts lists the files that can't be merged automatically.
+type MergeConflicts struct {
+	Target string
+	Files  []FileConflict
+	// Committed is true when the conflicts were resolved with a merge strategy,
+	// and the merge was committed.
+	Committed bool
+}
+
+// FileConflict is a file that can't be merged automatically.
+type FileConflict struct {
+	Path   string
+	Reason string
+	Hunks  []diff3.Conflict
+}
+
+func (m *MergeConflicts) Error() string {
+	paths := make([]string, 0, len(m.Files))
+	for _, f := range m.Files {
+		paths = append(paths, f.Path)
+	}
+	return fmt.Sprintf("%v: merging %s, conflicting files: %s",
+		ErrMergeConflicts, m.Target, strings.Join(paths, ", "))
+}
+
+func (m *MergeConflicts) Unwrap() error {
+	return ErrMergeConflicts
+}
+
+// Details returns a description of each conflict, with line numbers.
+func (m *MergeConflicts) Details() string {
+	var sb strings.Builder
+	for _, f := range m.Files {
+		if len(f.Hunks) == 0 {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", f.Path, f.Reason))
+			continue
+		}
+		for _, h := range f.Hunks {
+			sb.WriteString(fmt.Sprintf("%s:%d: %s (%d lines ours, "+
+				"%d lines theirs)\n", f.Path, h.Line, f.Reason,
+				len(h.Ours), len(h.Theirs)))
+		}
+	}
+	return sb.String()
+}
+
+const mergeLogLimit = 20
+
+// Merge merges the given branch into the current HEAD, with a three-way merge
+// of trees and file contents. When the merge can't be performed automatically,
+// the MergeConflicts error is returned, and the worktree is left untouched,
+// unless a merge strategy resolving the conflicts is given.
+//
+// The worktree must not have uncommitted changes, as it's reset to the merge
+// result. Criss-cross merges, with more than one merge base, aren't supported,
+// as the merge bases would have to be merged recursively first.
+func (r Repository) Merge(remote *git.Remote, branch string, opts ...git.MergeOption) error {
+	options := git.MergeOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
 	if remote != nil {
-		err = r.Fetch(*remote)
-		if err != nil {
+		if err := r.Fetch(*remote); err != nil {
 			return errors.Wrap(err, ErrRemoteOperationFailed)
 		}
 	}
-	before, err = r.Head()
+	head, err := r.Head()
 	if err != nil {
 		return errors.Wrap(err, ErrLocalOperationFailed)
 	}
-
 	targetBranch := branch
+	revision := plumbing.Revision(plumbing.NewBranchReferenceName(branch))
 	if remote != nil {
 		targetBranch = fmt.Sprintf("%s/%s", remote.Name, branch)
+		revision = plumbing.Revision(
+			plumbing.NewRemoteReferenceName(remote.Name, branch))
 	}
-	// TODO: Consider rewriting this to Go native code.
-	err = pkgfiles.WithinDirectory(r.Path, func() error {
-		return errors.Wrap(sh.Run("git", "merge", "--commit",
-			"--quiet", "--log", "-m", "Merge "+targetBranch, targetBranch),
-			ErrRemoteOperationFailed)
-	})
+	ours, theirs, err := r.mergeHeads(head.Hash(), revision)
+	if err != nil {
+		return err
+	}
+	bases, err := ours.MergeBase(theirs)
+	if err != nil {
+		return errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	if len(bases) == 0 {
+		return fmt.Errorf("%w: refusing to merge unrelated histories of %s",
+			ErrLocalOperationFailed, targetBranch)
+	}
+	if len(bases) > 1 {
+		return fmt.Errorf("%w: refusing criss-cross merge of %s, "+
+			"with %d merge bases", ErrLocalOperationFailed, targetBranch, len(bases))
+	}
+	base := bases[0]
+	if base.Hash == theirs.Hash {
+		return gitv5.NoErrAlreadyUpToDate
+	}
+	if err = r.ensureClean(); err != nil {
+		return err
+	}
+	if base.Hash == ours.Hash {
+		return r.fastForward(head, theirs.Hash)
+	}
+	m := treeMerge{repo: r, target: targetBranch, strategy: options.Strategy}
+	entries, err := m.merge(base, ours, theirs)
+	if err != nil {
+		return err
+	}
+	message, err := r.mergeMessage(targetBranch, ours, theirs, m.conflicts)
+	if err != nil {
+		return err
+	}
+	if options.Message != nil {
+		message = options.Message(message, theirs.Hash)
+	}
+	if err = r.commitMerge(entries, message, ours.Hash, theirs.Hash); err != nil {
+		return err
+	}
+	if len(m.conflicts) > 0 {
+		return &MergeConflicts{
+			Target:    targetBranch,
+			Files:     m.conflicts,
+			Committed: true,
+		}
+	}
+	return nil
+}
+
+func (r Repository) mergeHeads(
+	head plumbing.Hash, revision plumbing.Revision,
+) (*object.Commit, *object.Commit, error) {
+	target, err := r.ResolveRevision(revision)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	ours, err := r.CommitObject(head)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	theirs, err := r.CommitObject(*target)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	return ours, theirs, nil
+}
+
+// ensureClean checks the worktree has no uncommitted changes, as those would
+// be lost when the worktree is reset to the merge result. Untracked files are
+// left in place by the reset.
+func (r Repository) ensureClean() error {
+	wt, err := r.Worktree()
+	if err != nil {
+		return errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	st, err := wt.Status()
+	if err != nil {
+		return errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	dirty := make([]string, 0, len(st))
+	for pth, fs := range st {
+		if fs.Worktree == gitv5.Untracked || fs.Staging == gitv5.Untracked {
+			continue
+		}
+		dirty = append(dirty, pth)
+	}
+	if len(dirty) == 0 {
+		return nil
+	}
+	sort.Strings(dirty)
+	return fmt.Errorf("%w: %w: %s", ErrLocalOperationFailed,
+		ErrDirtyWorktree, strings.Join(dirty, ", "))
+}
+
+func (r Repository) fastForward(head *plumbing.Reference, target plumbing.Hash) error {
+	wt, err := r.Worktree()
+	if err != nil {
+		return errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	err = r.Storer.SetReference(plumbing.NewHashReference(head.Name(), target))
+	if err != nil {
+		return errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	return errors.Wrap(wt.Reset(&gitv5.ResetOptions{
+		Commit: target,
+		Mode:   gitv5.HardReset,
+	}), ErrLocalOperationFailed)
+}
+
+func (r Repository) mergeMessage(
+	target string, ours, theirs *object.Commit, conflicts []FileConflict,
+) (string, error) {
+	commits, err := r.commitsBetween(ours, theirs, mergeLogLimit)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Merge %s\n\n* %s:\n", target, target))
+	for _, c := range commits {
+		sb.WriteString("  " + commitSubject(c) + "\n")
+	}
+	if len(conflicts) > 0 {
+		sb.WriteString("\nConflicts:\n")
+		for _, f := range conflicts {
+			sb.WriteString("\t" + f.Path + "\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+// commitsBetween returns the commits reachable from the until commit, but not
+// from the since commit, newest first, up to the given limit.
+func (r Repository) commitsBetween(since, until *object.Commit, limit int) ([]*object.Commit, error) {
+	seen := make(map[plumbing.Hash]bool)
+	err := object.NewCommitPreorderIter(since, nil, nil).
+		ForEach(func(c *object.Commit) error {
+			seen[c.Hash] = true
+			return nil
+		})
 	if err != nil {
-		_ = pkgfiles.WithinDirectory(r.Path, func() error {
-			return sh.Run("git", "merge", "--abort")
+		return nil, errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	commits := make([]*object.Commit, 0, limit)
+	iter := object.NewCommitPreorderIter(until, seen, nil)
+	defer iter.Close()
+	for len(commits) < limit {
+		c, ierr := iter.Next()
+		if errors.Is(ierr, io.EOF) {
+			break
+		}
+		if ierr != nil {
+			return nil, errors.Wrap(ierr, ErrLocalOperationFailed)
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
+func commitSubject(c *object.Commit) string {
+	subject, _, _ := strings.Cut(c.Message, "\n")
+	return subject
+}
+
+// commitMerge writes the merged entries into the index, commits them with
+// both parents, and updates the worktree to match.
+func (r Repository) commitMerge(
+	entries map[string]treeEntry, message string, parents ...plumbing.Hash,
+) error {
+	idx := &index.Index{Version: 2}
+	paths := make([]string, 0, len(entries))
+	for p := range entries {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		e := entries[p]
+		idx.Entries = append(idx.Entries, &index.Entry{
+			Name: p,
+			Hash: e.hash,
+			Mode: e.mode,
 		})
-		return errors.Wrap(err, ErrRemoteOperationFailed)
 	}
-	after, err = r.Head()
+	wt, err := r.Worktree()
+	if err != nil {
+		return errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	if err = r.removeFromWorktree(wt, entries); err != nil {
+		return err
+	}
+	if err = r.Storer.SetIndex(idx); err != nil {
+		return errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	opts := &gitv5.CommitOptions{
+		Parents:           parents,
+		AllowEmptyCommits: true,
+	}
+	if message, err = r.commitOptions(message, opts); err != nil {
+		return err
+	}
+	hash, err := wt.Commit(message, opts)
 	if err != nil {
 		return errors.Wrap(err, ErrLocalOperationFailed)
 	}
+	return errors.Wrap(wt.Reset(&gitv5.ResetOptions{
+		Commit: hash,
+		Mode:   gitv5.HardReset,
+	}), ErrLocalOperationFailed)
+}
 
-	if before.Hash() == after.Hash() {
-		err = gitv5.NoErrAlreadyUpToDate
+// removeFromWorktree removes files that are tracked now, but aren't present in
+// the given entries.
+func (r Repository) removeFromWorktree(wt *gitv5.Worktree, entries map[string]treeEntry) error {
+	idx, err := r.Storer.Index()
+	if err != nil {
+		return errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	for _, e := range idx.Entries {
+		if _, ok := entries[e.Name]; ok {
+			continue
+		}
+		if err = wt.Filesystem.Remove(e.Name); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, ErrLocalOperationFailed)
+		}
+	}
+	return nil
+}
+
+type treeEntry struct {
+	mode filemode.FileMode
+	hash plumbing.Hash
+}
+
+type treeMerge struct {
+	repo      Repository
+	target    string
+	strategy  git.MergeStrategy
+	conflicts []FileConflict
+}
+
+func (m *treeMerge) merge(base, ours, theirs *object.Commit) (map[string]treeEntry, error) {
+	trees := make([]map[string]treeEntry, 0, 3)
+	for _, c := range []*object.Commit{base, ours, theirs} {
+		entries, err := flattenTree(c)
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, entries)
+	}
+	baseEntries, oursEntries, theirsEntries := trees[0], trees[1], trees[2]
+	paths := make(map[string]bool)
+	for _, entries := range trees {
+		for p := range entries {
+			paths[p] = true
+		}
+	}
+	result := make(map[string]treeEntry, len(paths))
+	for p := range paths {
+		b, bok := baseEntries[p]
+		o, ook := oursEntries[p]
+		t, tok := theirsEntries[p]
+		var (
+			e   treeEntry
+			ok  bool
+			err error
+		)
+		switch {
+		case ook == tok && o == t:
+			e, ok = o, ook
+		case ook == bok && o == b:
+			e, ok = t, tok
+		case tok == bok && t == b:
+			e, ok = o, ook
+		case !ook || !tok:
+			m.conflict(p, "modified on one side, deleted on the other")
+			// The modified side is kept, unless resolving in favor of theirs.
+			e, ok = o, ook
+			if !ook || m.strategy == git.MergeTheirs {
+				e, ok = t, tok
+			}
+		default:
+			e, ok, err = m.mergeFile(p, b, o, t)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if ok {
+			result[p] = e
+		}
+	}
+	m.resolveCollisions(result, theirsEntries)
+	if len(m.conflicts) > 0 {
+		sort.Slice(m.conflicts, func(i, j int) bool {
+			return m.conflicts[i].Path < m.conflicts[j].Path
+		})
+	}
+	if len(m.conflicts) > 0 && m.strategy == git.MergeFail {
+		return nil, errors.Wrap(&MergeConflicts{
+			Target: m.target,
+			Files:  m.conflicts,
+		}, ErrLocalOperationFailed)
+	}
+	return result, nil
+}
+
+// resolveCollisions finds the files of one side, that are directories on the
+// other side, like a file a, and a file a/b, which can't be both in the tree.
+// Each collision is a conflict, resolved by keeping our side, unless
+// resolving in favor of theirs.
+func (m *treeMerge) resolveCollisions(result, theirs map[string]treeEntry) {
+	paths := make([]string, 0, len(result))
+	for p := range result {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		if _, ok := result[p]; !ok {
+			continue
+		}
+		for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+			if _, ok := result[dir]; !ok {
+				continue
+			}
+			m.conflict(dir, "file on one side, directory on the other")
+			_, theirsFile := theirs[dir]
+			if theirsFile == (m.strategy == git.MergeTheirs) {
+				for _, sub := range paths {
+					if strings.HasPrefix(sub, dir+"/") {
+						delete(result, sub)
+					}
+				}
+			} else {
+				delete(result, dir)
+			}
+			break
+		}
+	}
+}
+
+func (m *treeMerge) conflict(path, reason string, hunks ...diff3.Conflict) {
+	m.conflicts = append(m.conflicts, FileConflict{
+		Path:   path,
+		Reason: reason,
+		Hunks:  hunks,
+	})
+}
+
+// mergeFile merges the content of a file modified on both sides. The base
+// entry is zero, if the file was added on both sides. Conflicting files, that
+// can't hold conflict markers, are resolved in favor of theirs.
+func (m *treeMerge) mergeFile(path string, base, ours, theirs treeEntry) (treeEntry, bool, error) {
+	if !isText(ours.mode) || !isText(theirs.mode) {
+		m.conflict(path, "changed on both sides")
+		return theirs, true, nil
+	}
+	contents := make([]string, 0, 3)
+	for _, e := range []treeEntry{base, ours, theirs} {
+		content := ""
+		if !e.hash.IsZero() {
+			var err error
+			if content, err = m.repo.blobContent(e.hash.String()); err != nil {
+				return treeEntry{}, false, err
+			}
+		}
+		if strings.IndexByte(content, 0) >= 0 {
+			m.conflict(path, "binary file changed on both sides")
+			return theirs, true, nil
+		}
+		contents = append(contents, content)
+	}
+	merged := diff3.Merge(contents[0], contents[1], contents[2], diff3.Labels{
+		Ours: "HEAD", Theirs: m.target,
+	})
+	content := merged.Content
+	if !merged.Clean() {
+		m.conflict(path, "changed on both sides", merged.Conflicts...)
+		if m.strategy == git.MergeTheirs {
+			content = merged.Theirs
+		}
+	}
+	hash, err := m.repo.writeBlob(content)
+	if err != nil {
+		return treeEntry{}, false, err
+	}
+	mode := ours.mode
+	if ours.mode == base.mode {
+		mode = theirs.mode
+	}
+	return treeEntry{mode: mode, hash: hash}, true, nil
+}
+
+func isText(mode filemode.FileMode) bool {
+	return mode == filemode.Regular || mode == filemode.Executable ||
+		mode == filemode.Deprecated
+}
+
+func (r Repository) writeBlob(content string) (plumbing.Hash, error) {
+	obj := r.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	if _, err = io.Copy(w, bytes.NewBufferString(content)); err != nil {
+		return plumbing.ZeroHash, errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	if err = w.Close(); err != nil {
+		return plumbing.ZeroHash, errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	hash, err := r.Storer.SetEncodedObject(obj)
+	return hash, errors.Wrap(err, ErrLocalOperationFailed)
+}
+
+func flattenTree(c *object.Commit) (map[string]treeEntry, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	entries := make(map[string]treeEntry)
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, e, werr := walker.Next()
+		if errors.Is(werr, io.EOF) {
+			break
+		}
+		if werr != nil {
+			return nil, errors.Wrap(werr, ErrLocalOperationFailed)
+		}
+		if e.Mode == filemode.Dir {
+			continue
+		}
+		entries[name] = treeEntry{mode: e.Mode, hash: e.Hash}
 	}
-	return err
+	return entries, nil
 }
@@ -0,0 +1,345 @@
+package git_test
+
+import (
+	"testing"
+
+	gitv5 "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/openshift-knative/deviate/pkg/config"
+	configgit "github.com/openshift-knative/deviate/pkg/config/git"
+	"github.com/openshift-knative/deviate/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_Merge(t *testing.T) {
+	const base = "one\ntwo\nthree\nfour\nfive\n"
+	tcs := []struct {
+		name      string
+		ours      map[string]string
+		theirs    map[string]string
+		strategy  configgit.MergeStrategy
+		want      map[string]string
+		parents   int
+		wantErr   error
+		conflicts []string
+	}{{
+		name:    "fast-forward",
+		theirs:  map[string]string{"a.txt": "one\n"},
+		want:    map[string]string{"a.txt": "one\n"},
+		parents: 1,
+	}, {
+		name:    "up to date",
+		ours:    map[string]string{"a.txt": "one\n"},
+		want:    map[string]string{"a.txt": "one\n"},
+		wantErr: gitv5.NoErrAlreadyUpToDate,
+	}, {
+		name:   "clean",
+		ours:   map[string]string{"a.txt": "ONE\ntwo\nthree\nfour\nfive\n", "b.txt": "b\n"},
+		theirs: map[string]string{"a.txt": "one\ntwo\nthree\nfour\nFIVE\n", "c.txt": "c\n"},
+		want: map[string]string{
+			"a.txt": "ONE\ntwo\nthree\nfour\nFIVE\n",
+			"b.txt": "b\n",
+			"c.txt": "c\n",
+		},
+		parents: 2,
+	}, {
+		name:      "conflict",
+		ours:      map[string]string{"a.txt": "one\ntwo\nTHREE\nfour\nfive\n"},
+		theirs:    map[string]string{"a.txt": "one\ntwo\n3\nfour\nfive\n"},
+		want:      map[string]string{"a.txt": "one\ntwo\nTHREE\nfour\nfive\n"},
+		wantErr:   git.ErrMergeConflicts,
+		conflicts: []string{"a.txt"},
+	}, {
+		name:      "conflict with markers",
+		ours:      map[string]string{"a.txt": "one\ntwo\nTHREE\nfour\nfive\n"},
+		theirs:    map[string]string{"a.txt": "one\ntwo\n3\nfour\nfive\n"},
+		strategy:  configgit.MergeMarkers,
+		want:      map[string]string{"a.txt": "one\ntwo\n<<<<<<< HEAD\nTHREE\n=======\n3\n>>>>>>> theirs\nfour\nfive\n"},
+		parents:   2,
+		wantErr:   git.ErrMergeConflicts,
+		conflicts: []string{"a.txt"},
+	}, {
+		name:      "conflict resolved with theirs",
+		ours:      map[string]string{"a.txt": "one\ntwo\nTHREE\nfour\nfive\n"},
+		theirs:    map[string]string{"a.txt": "one\ntwo\n3\nfour\nfive\n"},
+		strategy:  configgit.MergeTheirs,
+		want:      map[string]string{"a.txt": "one\ntwo\n3\nfour\nfive\n"},
+		parents:   2,
+		wantErr:   git.ErrMergeConflicts,
+		conflicts: []string{"a.txt"},
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			gr, err := gitv5.PlainInit(projectPath, false)
+			require.NoError(t, err)
+			cfg, err := gr.Config()
+			require.NoError(t, err)
+			cfg.User.Name = "test"
+			cfg.User.Email = "test@example.org"
+			require.NoError(t, gr.SetConfig(cfg))
+			commitFiles(t, gr, projectPath, map[string]string{"a.txt": base})
+			head, err := gr.Head()
+			require.NoError(t, err)
+			wt, err := gr.Worktree()
+			require.NoError(t, err)
+			if tc.theirs != nil {
+				require.NoError(t, wt.Checkout(&gitv5.CheckoutOptions{
+					Branch: plumbing.NewBranchReferenceName("theirs"),
+					Create: true,
+				}))
+				commitFiles(t, gr, projectPath, tc.theirs)
+			} else {
+				require.NoError(t, gr.Storer.SetReference(plumbing.NewHashReference(
+					plumbing.NewBranchReferenceName("theirs"), head.Hash())))
+			}
+			require.NoError(t, wt.Checkout(&gitv5.CheckoutOptions{
+				Branch: head.Name(),
+			}))
+			if tc.ours != nil {
+				commitFiles(t, gr, projectPath, tc.ours)
+			}
+			repo := &git.Repository{
+				Context:    t.Context(),
+				Project:    config.Project{Path: projectPath},
+				Repository: gr,
+			}
+
+			err = repo.Merge(nil, "theirs", configgit.WithMergeStrategy(tc.strategy))
+
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			var conflicts *git.MergeConflicts
+			if len(tc.conflicts) > 0 {
+				require.ErrorAs(t, err, &conflicts)
+				got := make([]string, 0, len(conflicts.Files))
+				for _, f := range conflicts.Files {
+					got = append(got, f.Path)
+				}
+				assert.Equal(t, tc.conflicts, got)
+				assert.Contains(t, conflicts.Details(), "a.txt:3:")
+				assert.Equal(t, tc.strategy != configgit.MergeFail, conflicts.Committed)
+			}
+			for name, content := range tc.want {
+				assert.Equal(t, content, readFile(t, projectPath, name))
+			}
+			if tc.parents > 0 {
+				h, herr := gr.Head()
+				require.NoError(t, herr)
+				c, cerr := gr.CommitObject(h.Hash())
+				require.NoError(t, cerr)
+				assert.Len(t, c.ParentHashes, tc.parents)
+			}
+			st, err := wt.Status()
+			require.NoError(t, err)
+			assert.True(t, st.IsClean(), st.String())
+		})
+	}
+}
+
+func TestRepository_MergeFileDirectoryCollision(t *testing.T) {
+	tcs := []struct {
+		name     string
+		strategy configgit.MergeStrategy
+		want     map[string]string
+		absent   string
+	}{{
+		name: "fail",
+	}, {
+		name:     "markers keep ours",
+		strategy: configgit.MergeMarkers,
+		want:     map[string]string{"a": "file\n", "c.txt": "c\n"},
+		absent:   "a/b",
+	}, {
+		name:     "theirs",
+		strategy: configgit.MergeTheirs,
+		want:     map[string]string{"a/b": "b\n", "a/c": "c\n", "c.txt": "c\n"},
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			gr, projectPath, repo := newMergeRepo(t)
+			commitFiles(t, gr, projectPath, map[string]string{"c.txt": "c\n"})
+			head, err := gr.Head()
+			require.NoError(t, err)
+			wt, err := gr.Worktree()
+			require.NoError(t, err)
+			require.NoError(t, wt.Checkout(&gitv5.CheckoutOptions{
+				Branch: plumbing.NewBranchReferenceName("theirs"),
+				Create: true,
+			}))
+			commitFiles(t, gr, projectPath, map[string]string{"a/b": "b\n", "a/c": "c\n"})
+			require.NoError(t, wt.Checkout(&gitv5.CheckoutOptions{Branch: head.Name()}))
+			commitFiles(t, gr, projectPath, map[string]string{"a": "file\n"})
+			ours, err := gr.Head()
+			require.NoError(t, err)
+
+			err = repo.Merge(nil, "theirs", configgit.WithMergeStrategy(tc.strategy))
+
+			require.ErrorIs(t, err, git.ErrMergeConflicts)
+			var conflicts *git.MergeConflicts
+			require.ErrorAs(t, err, &conflicts)
+			require.Len(t, conflicts.Files, 1)
+			assert.Equal(t, "a", conflicts.Files[0].Path)
+			assert.Equal(t, tc.strategy != configgit.MergeFail, conflicts.Committed)
+			current, err := gr.Head()
+			require.NoError(t, err)
+			c, err := gr.CommitObject(current.Hash())
+			require.NoError(t, err)
+			if tc.strategy == configgit.MergeFail {
+				assert.Equal(t, ours.Hash(), current.Hash())
+				return
+			}
+			require.Len(t, c.ParentHashes, 2)
+			tree, err := c.Tree()
+			require.NoError(t, err)
+			for name, content := range tc.want {
+				f, ferr := tree.File(name)
+				require.NoError(t, ferr, name)
+				got, ferr := f.Contents()
+				require.NoError(t, ferr)
+				assert.Equal(t, content, got, name)
+				assert.Equal(t, content, readFile(t, projectPath, name))
+			}
+			if tc.absent != "" {
+				_, err = tree.File(tc.absent)
+				require.ErrorIs(t, err, object.ErrFileNotFound)
+			}
+			st, err := wt.Status()
+			require.NoError(t, err)
+			assert.True(t, st.IsClean(), st.String())
+		})
+	}
+}
+
+func TestRepository_MergeDirtyWorktree(t *testing.T) {
+	gr, projectPath, repo := newMergeRepo(t)
+	commitFiles(t, gr, projectPath, map[string]string{"a.txt": "one\n"})
+	head, err := gr.Head()
+	require.NoError(t, err)
+	wt, err := gr.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, wt.Checkout(&gitv5.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName("theirs"),
+		Create: true,
+	}))
+	commitFiles(t, gr, projectPath, map[string]string{"b.txt": "b\n"})
+	require.NoError(t, wt.Checkout(&gitv5.CheckoutOptions{Branch: head.Name()}))
+	writeFile(t, projectPath, "a.txt", "uncommitted\n")
+	writeFile(t, projectPath, "untracked.txt", "untracked\n")
+
+	err = repo.Merge(nil, "theirs")
+
+	require.ErrorIs(t, err, git.ErrDirtyWorktree)
+	assert.ErrorContains(t, err, "a.txt")
+	assert.Equal(t, "uncommitted\n", readFile(t, projectPath, "a.txt"))
+	current, err := gr.Head()
+	require.NoError(t, err)
+	assert.Equal(t, head.Hash(), current.Hash())
+}
+
+func TestRepository_MergeCrissCross(t *testing.T) {
+	gr, projectPath, repo := newMergeRepo(t)
+	commitFiles(t, gr, projectPath, map[string]string{"a.txt": "a\n"})
+	head, err := gr.Head()
+	require.NoError(t, err)
+	wt, err := gr.Worktree()
+	require.NoError(t, err)
+	theirs := plumbing.NewBranchReferenceName("theirs")
+	require.NoError(t, wt.Checkout(&gitv5.CheckoutOptions{Branch: theirs, Create: true}))
+	commitFiles(t, gr, projectPath, map[string]string{"t.txt": "t\n"})
+	theirsCommit, err := gr.Head()
+	require.NoError(t, err)
+	require.NoError(t, wt.Checkout(&gitv5.CheckoutOptions{Branch: head.Name()}))
+	commitFiles(t, gr, projectPath, map[string]string{"o.txt": "o\n"})
+	oursCommit, err := gr.Head()
+	require.NoError(t, err)
+	// Each side merges the other one, so both are merge bases afterward.
+	require.NoError(t, repo.Merge(nil, "theirs"))
+	require.NoError(t, wt.Checkout(&gitv5.CheckoutOptions{Branch: theirs, Force: true}))
+	require.NoError(t, gr.Storer.SetReference(plumbing.NewHashReference(
+		plumbing.NewBranchReferenceName("ours"), oursCommit.Hash())))
+	require.NoError(t, repo.Merge(nil, "ours"))
+	require.NoError(t, wt.Checkout(&gitv5.CheckoutOptions{Branch: head.Name(), Force: true}))
+	commitFiles(t, gr, projectPath, map[string]string{"o.txt": "o2\n"})
+	require.NotEqual(t, theirsCommit.Hash(), oursCommit.Hash())
+
+	err = repo.Merge(nil, "theirs")
+
+	require.ErrorIs(t, err, git.ErrLocalOperationFailed)
+	assert.ErrorContains(t, err, "criss-cross")
+}
+
+func TestRepository_MergeMessage(t *testing.T) {
+	gr, projectPath, repo := newMergeRepo(t)
+	commitFiles(t, gr, projectPath, map[string]string{"a.txt": "a\n"})
+	head, err := gr.Head()
+	require.NoError(t, err)
+	upstreamPath := t.TempDir()
+	upstream, err := gitv5.PlainClone(upstreamPath, false, &gitv5.CloneOptions{
+		URL: projectPath,
+	})
+	require.NoError(t, err)
+	remote := &configgit.Remote{Name: "upstream", URL: upstreamPath}
+	var merged plumbing.Hash
+	decorate := configgit.WithMessage(func(message string, hash plumbing.Hash) string {
+		merged = hash
+		return message + "\nUpstream-Commit: " + hash.String() + "\n"
+	})
+	// The upstream moves between the merges, the message has to reflect the
+	// commit fetched by each merge.
+	for i, content := range []string{"u1\n", "u2\n"} {
+		commitFiles(t, upstream, upstreamPath, map[string]string{"u.txt": content})
+		upstreamHead, herr := upstream.Head()
+		require.NoError(t, herr)
+		commitFiles(t, gr, projectPath, map[string]string{"o.txt": content})
+
+		require.NoError(t, repo.Merge(remote, head.Name().Short(), decorate), i)
+
+		assert.Equal(t, upstreamHead.Hash(), merged, i)
+		current, herr := gr.Head()
+		require.NoError(t, herr)
+		c, cerr := gr.CommitObject(current.Hash())
+		require.NoError(t, cerr)
+		require.Len(t, c.ParentHashes, 2)
+		assert.Equal(t, upstreamHead.Hash(), c.ParentHashes[1], i)
+		assert.Contains(t, c.Message, "Upstream-Commit: "+upstreamHead.Hash().String())
+		assert.Equal(t, content, readFile(t, projectPath, "u.txt"))
+	}
+}
+
+func newMergeRepo(tb testing.TB) (*gitv5.Repository, string, *git.Repository) {
+	tb.Helper()
+	projectPath := tb.TempDir()
+	gr, err := gitv5.PlainInit(projectPath, false)
+	require.NoError(tb, err)
+	cfg, err := gr.Config()
+	require.NoError(tb, err)
+	cfg.User.Name = "test"
+	cfg.User.Email = "test@example.org"
+	require.NoError(tb, gr.SetConfig(cfg))
+	return gr, projectPath, &git.Repository{
+		Context:    tb.Context(),
+		Project:    config.Project{Path: projectPath},
+		Repository: gr,
+	}
+}
+
+func commitFiles(tb testing.TB, gr *gitv5.Repository, dir string, files map[string]string) {
+	tb.Helper()
+	wt, err := gr.Worktree()
+	require.NoError(tb, err)
+	for name, content := range files {
+		writeFile(tb, dir, name, content)
+		_, err = wt.Add(name)
+		require.NoError(tb, err)
+	}
+	_, err = wt.Commit("change", &gitv5.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.org"},
+	})
+	require.NoError(tb, err)
+}
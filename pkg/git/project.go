@@ -22,7 +22,9 @@ var (
 
 // NewProject creates a new Project from regular config.Project.
 func NewProject(project config.Project, state state.State) (Project, error) {
-	r, err := gitv5.PlainOpen(project.Path)
+	r, err := gitv5.PlainOpenWithOptions(project.Path, &gitv5.PlainOpenOptions{
+		EnableDotGitCommonDir: true,
+	})
 	if err != nil {
 		return Project{}, fmt.Errorf("%s - %w: %w",
 			project.Path, ErrNotGitRepo, err)
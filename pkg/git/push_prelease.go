@@ -2,6 +2,7 @@ package git
 
 import (
 	"fmt"
+	"strings"
 
 	gitv5 "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
@@ -10,7 +11,17 @@ import (
 	"github.com/openshift-knative/deviate/pkg/errors"
 )
 
-func (r Repository) Push(remote git.Remote, refname plumbing.ReferenceName) error {
+// ErrPushRejected when the remote reference can't be updated, as it has
+// changes that would be lost.
+var ErrPushRejected = errors.New("push rejected")
+
+// Push pushes the reference to the remote. Only fast-forward updates are
+// pushed, unless the options allow the remote reference to be overwritten.
+func (r Repository) Push(remote git.Remote, refname plumbing.ReferenceName, opts ...git.PushOption) error {
+	o := git.PushOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	repo := r.Repository
 	specs := []config.RefSpec{
 		refSpecForReferenceName(refname),
@@ -20,20 +31,82 @@ func (r Repository) Push(remote git.Remote, refname plumbing.ReferenceName) erro
 		return err
 	}
 
-	auth, err := authentication(remote)
+	auth, err := r.authentication(remote)
 	if err != nil {
 		return errors.Wrap(err, ErrLocalOperationFailed)
 	}
-	err = repo.PushContext(r.Context, &gitv5.PushOptions{
+	po := &gitv5.PushOptions{
 		RemoteName: remote.Name,
 		RefSpecs:   specs,
 		Auth:       auth,
-		Force:      true,
+		Force:      o.Force,
+	}
+	if o.Lease != nil {
+		if err = r.checkLease(remote, refname, *o.Lease); err != nil {
+			return err
+		}
+		if !o.Lease.IsZero() {
+			// Verified again by the remote, in the same push session.
+			po.Force = true
+			po.RequireRemoteRefs = []config.RefSpec{
+				config.RefSpec(o.Lease.String() + ":" + refname.String()),
+			}
+		}
+	}
+	err = repo.PushContext(r.Context, po)
+	if errors.Is(err, gitv5.NoErrAlreadyUpToDate) {
+		return nil
+	}
+	if err != nil && strings.Contains(err.Error(), "non-fast-forward") {
+		return fmt.Errorf("%w: %s on %s has changes missing locally, "+
+			"refusing to overwrite them: %w", ErrPushRejected, refname, remote.Name, err)
+	}
+	return remoteError(remote, err)
+}
+
+// checkLease verifies the remote reference still points to the expected hash.
+func (r Repository) checkLease(remote git.Remote, refname plumbing.ReferenceName, expected plumbing.Hash) error {
+	refs, err := r.ListRemote(remote)
+	if err != nil {
+		return err
+	}
+	current := plumbing.ZeroHash
+	for _, ref := range refs {
+		if ref.Name() == refname {
+			current = ref.Hash()
+			break
+		}
+	}
+	if current == expected {
+		return nil
+	}
+	if expected.IsZero() {
+		return fmt.Errorf("%w: %s was created on %s, at %s, since it was observed",
+			ErrPushRejected, refname, remote.Name, current)
+	}
+	return fmt.Errorf("%w: %s on %s was expected at %s, but it is at %s",
+		ErrPushRejected, refname, remote.Name, expected, current)
+}
+
+// DeleteRemoteBranch deletes the branch on the remote.
+func (r Repository) DeleteRemoteBranch(remote git.Remote, branch string) error {
+	if err := r.ensureRemote(remote); err != nil {
+		return err
+	}
+	auth, err := r.authentication(remote)
+	if err != nil {
+		return errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	ref := plumbing.NewBranchReferenceName(branch)
+	err = r.Repository.PushContext(r.Context, &gitv5.PushOptions{
+		RemoteName: remote.Name,
+		RefSpecs:   []config.RefSpec{config.RefSpec(":" + ref.String())},
+		Auth:       auth,
 	})
 	if errors.Is(err, gitv5.NoErrAlreadyUpToDate) {
 		return nil
 	}
-	return errors.Wrap(err, ErrRemoteOperationFailed)
+	return remoteError(remote, err)
 }
 
 func (r Repository) DeleteBranch(branch string) error {
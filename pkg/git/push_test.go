@@ -0,0 +1,71 @@
+package git_test
+
+import (
+	"testing"
+
+	gitv5 "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/openshift-knative/deviate/pkg/config"
+	configgit "github.com/openshift-knative/deviate/pkg/config/git"
+	"github.com/openshift-knative/deviate/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_Push(t *testing.T) {
+	remotePath := t.TempDir()
+	_, err := gitv5.PlainInit(remotePath, true)
+	require.NoError(t, err)
+	remote := configgit.Remote{Name: "downstream", URL: "file://" + remotePath}
+	projectPath := t.TempDir()
+	gr, err := gitv5.PlainInit(projectPath, false)
+	require.NoError(t, err)
+	commitFiles(t, gr, projectPath, map[string]string{"a.txt": "one\n"})
+	first, err := gr.Head()
+	require.NoError(t, err)
+	repo := &git.Repository{
+		Context:    t.Context(),
+		Project:    config.Project{Path: projectPath},
+		Repository: gr,
+	}
+	branch := first.Name()
+
+	require.NoError(t, repo.Push(remote, branch,
+		configgit.WithLease(plumbing.ZeroHash)))
+
+	commitFiles(t, gr, projectPath, map[string]string{"a.txt": "two\n"})
+	require.NoError(t, repo.Push(remote, branch), "fast-forward")
+	second, err := gr.Head()
+	require.NoError(t, err)
+
+	// Rewrite the local history, as if the remote got a hotfix meanwhile.
+	require.NoError(t, gr.Storer.SetReference(first))
+	wt, err := gr.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, wt.Reset(&gitv5.ResetOptions{Mode: gitv5.HardReset}))
+	commitFiles(t, gr, projectPath, map[string]string{"b.txt": "b\n"})
+
+	err = repo.Push(remote, branch)
+	require.ErrorIs(t, err, git.ErrPushRejected)
+	err = repo.Push(remote, branch, configgit.WithLease(first.Hash()))
+	require.ErrorIs(t, err, git.ErrPushRejected)
+	err = repo.Push(remote, branch, configgit.WithLease(plumbing.ZeroHash))
+	require.ErrorIs(t, err, git.ErrPushRejected)
+	require.NoError(t, repo.Push(remote, branch, configgit.WithLease(second.Hash())))
+
+	refs, err := repo.ListRemote(remote)
+	require.NoError(t, err)
+	head, err := gr.Head()
+	require.NoError(t, err)
+	assert.Contains(t, refs, plumbing.NewHashReference(branch, head.Hash()))
+
+	syncBranch := plumbing.NewBranchReferenceName("ci/sync")
+	require.NoError(t, gr.Storer.SetReference(
+		plumbing.NewHashReference(syncBranch, head.Hash())))
+	require.NoError(t, repo.Push(remote, syncBranch, configgit.WithForce()))
+	require.NoError(t, repo.DeleteRemoteBranch(remote, syncBranch.Short()))
+	refs, err = repo.ListRemote(remote)
+	require.NoError(t, err)
+	assert.NotContains(t, refs, plumbing.NewHashReference(syncBranch, head.Hash()))
+	assert.Contains(t, refs, plumbing.NewHashReference(branch, head.Hash()))
+}
@@ -4,6 +4,7 @@ import (
 	gitv5 "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/openshift-knative/deviate/pkg/config/git"
 	"github.com/openshift-knative/deviate/pkg/errors"
@@ -15,7 +16,7 @@ func (r Repository) ListRemote(remote git.Remote) ([]*plumbing.Reference, error)
 		URLs: []string{remote.URL},
 	})
 
-	auth, err := authentication(remote)
+	auth, err := r.authentication(remote)
 	if err != nil {
 		return nil, err
 	}
@@ -23,8 +24,11 @@ func (r Repository) ListRemote(remote git.Remote) ([]*plumbing.Reference, error)
 		Auth: auth,
 	}
 	refs, err := rem.ListContext(r.Context, opts)
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return []*plumbing.Reference{}, nil
+	}
 	if err != nil {
-		return nil, errors.Wrap(err, ErrRemoteOperationFailed)
+		return nil, remoteError(remote, err)
 	}
 	return refs, nil
 }
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"fmt"
+
+	gitv5 "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/openshift-knative/deviate/pkg/config"
+	"github.com/openshift-knative/deviate/pkg/config/git"
+	"github.com/openshift-knative/deviate/pkg/errors"
+)
+
+// extendsRemote is the name of the remote the extended configuration files
+// are read from. As the files are read before the configuration is loaded,
+// the remote uses the default authentication, and the
+// DEVIATE_EXTENDS_TOKEN variable.
+const extendsRemote = "extends"
+
+// ReadRemoteFile reads the file out of a shallow, in-memory clone of the
+// remote repository.
+func (r Repository) ReadRemoteFile(file git.RemoteFile) ([]byte, error) {
+	remote := git.Remote{Name: extendsRemote, URL: file.URL}
+	auth, err := r.remoteAuthentication(remote, config.DefaultRemoteAuth())
+	if err != nil {
+		return nil, err
+	}
+	refs := []plumbing.ReferenceName{plumbing.HEAD}
+	if file.Ref != "" {
+		refs = []plumbing.ReferenceName{
+			plumbing.NewBranchReferenceName(file.Ref),
+			plumbing.NewTagReferenceName(file.Ref),
+		}
+	}
+	var repo *gitv5.Repository
+	for _, ref := range refs {
+		opts := &gitv5.CloneOptions{
+			URL:          file.URL,
+			Auth:         auth,
+			Depth:        1,
+			SingleBranch: true,
+			Tags:         gitv5.NoTags,
+		}
+		if ref != plumbing.HEAD {
+			opts.ReferenceName = ref
+		}
+		repo, err = gitv5.CloneContext(r.Context, memory.NewStorage(), nil, opts)
+		if !errors.Is(err, gitv5.NoMatchingRefSpecError{}) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, remoteError(remote, err)
+	}
+	return readFile(repo, file)
+}
+
+func readFile(repo *gitv5.Repository, file git.RemoteFile) ([]byte, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrRemoteOperationFailed)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, errors.Wrap(err, ErrRemoteOperationFailed)
+	}
+	f, err := commit.File(file.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s at %s of %s: %w", ErrRemoteOperationFailed,
+			file.Path, head.Name().Short(), file.URL, err)
+	}
+	contents, err := f.Contents()
+	return []byte(contents), errors.Wrap(err, ErrRemoteOperationFailed)
+}
@@ -0,0 +1,57 @@
+package git_test
+
+import (
+	"testing"
+
+	gitv5 "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	configgit "github.com/openshift-knative/deviate/pkg/config/git"
+	"github.com/openshift-knative/deviate/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_ReadRemoteFile(t *testing.T) {
+	remotePath := t.TempDir()
+	gr, err := gitv5.PlainInit(remotePath, false)
+	require.NoError(t, err)
+	commitFiles(t, gr, remotePath, map[string]string{"base.yaml": "syncLabels: [v1]\n"})
+	head, err := gr.Head()
+	require.NoError(t, err)
+	_, err = gr.CreateTag("v1", head.Hash(), nil)
+	require.NoError(t, err)
+	require.NoError(t, gr.CreateBranch(&config.Branch{Name: "stable"}))
+	require.NoError(t, gr.Storer.SetReference(plumbing.NewHashReference(
+		plumbing.NewBranchReferenceName("stable"), head.Hash())))
+	commitFiles(t, gr, remotePath, map[string]string{"base.yaml": "syncLabels: [v2]\n"})
+	repo := &git.Repository{Context: t.Context()}
+
+	for ref, want := range map[string]string{
+		"":       "syncLabels: [v2]\n",
+		"v1":     "syncLabels: [v1]\n",
+		"stable": "syncLabels: [v1]\n",
+	} {
+		t.Run("ref="+ref, func(t *testing.T) {
+			bytes, rerr := repo.ReadRemoteFile(configgit.RemoteFile{
+				URL:  "file://" + remotePath,
+				Ref:  ref,
+				Path: "base.yaml",
+			})
+			require.NoError(t, rerr)
+			assert.Equal(t, want, string(bytes))
+		})
+	}
+
+	_, err = repo.ReadRemoteFile(configgit.RemoteFile{
+		URL:  "file://" + remotePath,
+		Path: "missing.yaml",
+	})
+	require.ErrorIs(t, err, git.ErrRemoteOperationFailed)
+	_, err = repo.ReadRemoteFile(configgit.RemoteFile{
+		URL:  "file://" + remotePath,
+		Ref:  "missing",
+		Path: "base.yaml",
+	})
+	require.ErrorIs(t, err, git.ErrRemoteOperationFailed)
+}
@@ -2,8 +2,10 @@ package git
 
 import (
 	"context"
+	"fmt"
 
 	gitv5 "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/filesystem"
 	"github.com/openshift-knative/deviate/pkg/config"
 )
 
@@ -12,4 +14,19 @@ type Repository struct {
 	*gitv5.Repository
 	config.Project
 	context.Context
+	// Auth of the remotes, and Commits settings, are known after the
+	// configuration is loaded.
+	Auth    config.Auth
+	Commits config.Commits
+}
+
+// GitDir returns the git directory of the repository. It's outside the
+// project, if the project is a worktree, or a submodule.
+func (r Repository) GitDir() (string, error) {
+	st, ok := r.Storer.(*filesystem.Storage)
+	if !ok {
+		return "", fmt.Errorf("%w: the repository isn't stored on disk",
+			ErrLocalOperationFailed)
+	}
+	return st.Filesystem().Root(), nil
 }
@@ -0,0 +1,57 @@
+package git_test
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+
+	gitv5 "github.com/go-git/go-git/v5"
+	"github.com/openshift-knative/deviate/pkg/config"
+	"github.com/openshift-knative/deviate/pkg/git"
+	"github.com/openshift-knative/deviate/pkg/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_GitDir(t *testing.T) {
+	tmp := t.TempDir()
+	mainPath := path.Join(tmp, "main")
+	gr, err := gitv5.PlainInit(mainPath, false)
+	require.NoError(t, err)
+	commitFiles(t, gr, mainPath, map[string]string{"a.txt": "a\n"})
+	// The git directory of a submodule is within the one of the superproject.
+	submodulePath := path.Join(tmp, "submodule")
+	moduleDir := path.Join(tmp, "modules", "submodule")
+	_, err = gitv5.PlainInit(path.Join(tmp, "modules"), true)
+	require.NoError(t, err)
+	subRepo, err := gitv5.PlainInit(submodulePath, false)
+	require.NoError(t, err)
+	commitFiles(t, subRepo, submodulePath, map[string]string{"b.txt": "b\n"})
+	require.NoError(t, os.Rename(path.Join(submodulePath, ".git"), moduleDir))
+	require.NoError(t, os.WriteFile(path.Join(submodulePath, ".git"),
+		[]byte("gitdir: "+moduleDir+"\n"), 0o600))
+	worktreePath := path.Join(tmp, "worktree")
+	cmd := exec.Command("git", "worktree", "add", worktreePath)
+	cmd.Dir = mainPath
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	for projectPath, want := range map[string]string{
+		mainPath:      path.Join(mainPath, ".git"),
+		submodulePath: moduleDir,
+		worktreePath:  path.Join(mainPath, ".git", "worktrees", "worktree"),
+	} {
+		project, perr := git.NewProject(config.Project{Path: projectPath},
+			state.State{Context: t.Context()})
+		require.NoError(t, perr)
+		repo := project.Repository()
+
+		got, gerr := repo.GitDir()
+
+		require.NoError(t, gerr)
+		assert.Equal(t, want, got)
+		_, herr := repo.Head()
+		require.NoError(t, herr, projectPath)
+	}
+}
@@ -0,0 +1,188 @@
+package git
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	gitv5 "github.com/go-git/go-git/v5"
+	gitv5config "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mitchellh/go-homedir"
+	"github.com/openshift-knative/deviate/pkg/config"
+	"github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/provenance"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// ErrSigningKey when the commit signing key can't be used.
+var ErrSigningKey = errors.New("invalid signing key")
+
+// commitOptions applies the configured identity, and signature to the
+// options, and returns the message with the sign-off trailer, if enabled.
+func (r Repository) commitOptions(message string, opts *gitv5.CommitOptions) (string, error) {
+	cfg := r.Commits
+	now := time.Now()
+	if id := cfg.Author; id.Name != "" || id.Email != "" {
+		opts.Author = &object.Signature{Name: id.Name, Email: id.Email, When: now}
+	}
+	if id := cfg.Committer; id.Name != "" || id.Email != "" {
+		opts.Committer = &object.Signature{Name: id.Name, Email: id.Email, When: now}
+	}
+	if err := r.sign(opts); err != nil {
+		return "", err
+	}
+	if !cfg.SignOff {
+		return message, nil
+	}
+	id, err := r.committer(opts)
+	if err != nil {
+		return "", err
+	}
+	return provenance.AppendTrailers(message, provenance.Trailer{
+		Key:   "Signed-off-by",
+		Value: fmt.Sprintf("%s <%s>", id.Name, id.Email),
+	}), nil
+}
+
+// committer returns the identity the commit will be committed with.
+func (r Repository) committer(opts *gitv5.CommitOptions) (config.Identity, error) {
+	for _, sig := range []*object.Signature{opts.Committer, opts.Author} {
+		if sig != nil {
+			return config.Identity{Name: sig.Name, Email: sig.Email}, nil
+		}
+	}
+	cfg, err := r.ConfigScoped(gitv5config.SystemScope)
+	if err != nil {
+		return config.Identity{}, errors.Wrap(err, ErrLocalOperationFailed)
+	}
+	if cfg.Committer.Name != "" && cfg.Committer.Email != "" {
+		return config.Identity{Name: cfg.Committer.Name, Email: cfg.Committer.Email}, nil
+	}
+	if cfg.User.Name != "" && cfg.User.Email != "" {
+		return config.Identity{Name: cfg.User.Name, Email: cfg.User.Email}, nil
+	}
+	return config.Identity{}, errors.Wrap(gitv5.ErrMissingAuthor, ErrLocalOperationFailed)
+}
+
+func (r Repository) sign(opts *gitv5.CommitOptions) error {
+	cfg := r.Commits.Signing
+	if cfg.Format == "" {
+		return nil
+	}
+	file, err := homedir.Expand(cfg.Key)
+	if err != nil {
+		return errors.Wrap(err, ErrSigningKey)
+	}
+	key, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSigningKey, err)
+	}
+	switch cfg.Format {
+	case "gpg":
+		opts.SignKey, err = gpgKey(cfg, key)
+	case "ssh":
+		var signer gossh.Signer
+		if signer, err = parseKey(cfg.Key, key, cfg.PassphraseEnv); err == nil {
+			opts.Signer = sshSigner{signer}
+		}
+	default:
+		err = fmt.Errorf("%w: unsupported format %q", ErrSigningKey, cfg.Format)
+	}
+	return err
+}
+
+func gpgKey(cfg config.Signing, key []byte) (*openpgp.Entity, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(key))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrSigningKey, cfg.Key, err)
+	}
+	for _, entity := range entities {
+		if entity.PrivateKey == nil {
+			continue
+		}
+		if entity.PrivateKey.Encrypted {
+			passphrase := ""
+			if cfg.PassphraseEnv != "" {
+				passphrase = os.Getenv(cfg.PassphraseEnv)
+			}
+			if err = entity.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+				return nil, fmt.Errorf("%w: %s can't be decrypted with the "+
+					"passphrase variable: %w", ErrSigningKey, cfg.Key, err)
+			}
+		}
+		return entity, nil
+	}
+	return nil, fmt.Errorf("%w: %s has no private key", ErrSigningKey, cfg.Key)
+}
+
+// sshSigner signs the commits in the SSH signature format, as git does with
+// the gpg.format=ssh setting.
+type sshSigner struct {
+	signer gossh.Signer
+}
+
+const (
+	sshSigMagic     = "SSHSIG"
+	sshSigNamespace = "git"
+	sshSigHash      = "sha512"
+)
+
+func (s sshSigner) Sign(message io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(message)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrSigningKey)
+	}
+	digest := sha512.Sum512(data)
+	signed := append([]byte(sshSigMagic), gossh.Marshal(struct {
+		Namespace string
+		Reserved  string
+		Hash      string
+		Digest    string
+	}{sshSigNamespace, "", sshSigHash, string(digest[:])})...)
+	sig, err := s.sign(signed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSigningKey, err)
+	}
+	blob := append([]byte(sshSigMagic), gossh.Marshal(struct {
+		Version   uint32
+		PublicKey string
+		Namespace string
+		Reserved  string
+		Hash      string
+		Signature string
+	}{
+		1, string(s.signer.PublicKey().Marshal()),
+		sshSigNamespace, "", sshSigHash, string(gossh.Marshal(sig)),
+	})...)
+	return armorSSHSignature(blob), nil
+}
+
+func (s sshSigner) sign(data []byte) (*gossh.Signature, error) {
+	if s.signer.PublicKey().Type() == gossh.KeyAlgoRSA {
+		if as, ok := s.signer.(gossh.AlgorithmSigner); ok {
+			return as.SignWithAlgorithm(rand.Reader, data, gossh.KeyAlgoRSASHA512) //nolint:wrapcheck
+		}
+	}
+	return s.signer.Sign(rand.Reader, data) //nolint:wrapcheck
+}
+
+func armorSSHSignature(blob []byte) []byte {
+	const width = 70
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	var buf bytes.Buffer
+	buf.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > width {
+		buf.WriteString(encoded[:width] + "\n")
+		encoded = encoded[width:]
+	}
+	buf.WriteString(encoded + "\n")
+	buf.WriteString("-----END SSH SIGNATURE-----\n")
+	return buf.Bytes()
+}
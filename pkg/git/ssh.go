@@ -0,0 +1,158 @@
+package git
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/mitchellh/go-homedir"
+	"github.com/openshift-knative/deviate/pkg/config"
+	"github.com/openshift-knative/deviate/pkg/config/git"
+	"github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/skeema/knownhosts"
+	sshagent "github.com/xanzy/ssh-agent"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+var (
+	// ErrSSHKey when the SSH key can't be used.
+	ErrSSHKey = errors.New("invalid SSH key")
+	// ErrHostKeyMismatch when the host key of the remote differs from the
+	// known one.
+	ErrHostKeyMismatch = errors.New("host key mismatch")
+	// ErrUnknownHost when the remote host isn't in the known hosts.
+	ErrUnknownHost = errors.New("unknown host")
+)
+
+// defaultKeys are tried, if no keys are configured, and the SSH agent isn't
+// available.
+var defaultKeys = []string{ //nolint:gochecknoglobals
+	"~/.ssh/id_ed25519",
+	"~/.ssh/id_ecdsa",
+	"~/.ssh/id_rsa",
+}
+
+func sshAuthentication(remote git.Remote, cfg config.SSHAuth) (transport.AuthMethod, error) { //nolint:ireturn
+	user := cfg.User
+	if addr, err := ParseAddress(remote.URL); err == nil && addr.User != "" {
+		user = addr.User
+	}
+	hostKeys, err := hostKeyCallback(cfg.KnownHosts)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Keys) == 0 && sshagent.Available() {
+		auth, aerr := ssh.NewSSHAgentAuth(user)
+		if aerr != nil {
+			return nil, errors.Wrap(aerr, ErrRemoteOperationFailed)
+		}
+		auth.HostKeyCallback = hostKeys
+		return auth, nil
+	}
+	signers, err := sshSigners(remote, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &ssh.PublicKeysCallback{
+		User: user,
+		Callback: func() ([]gossh.Signer, error) {
+			return signers, nil
+		},
+		HostKeyCallbackHelper: ssh.HostKeyCallbackHelper{
+			HostKeyCallback: hostKeys,
+		},
+	}, nil
+}
+
+// hostKeyCallback verifies the host keys against the known hosts file. Nil
+// is returned for the default known hosts files, so the host key algorithms
+// are negotiated based on them.
+func hostKeyCallback(knownHosts string) (gossh.HostKeyCallback, error) {
+	if knownHosts == "" {
+		return nil, nil
+	}
+	file, err := homedir.Expand(knownHosts)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrRemoteOperationFailed)
+	}
+	cb, err := knownhosts.New(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: known hosts %s: %w",
+			ErrRemoteOperationFailed, knownHosts, err)
+	}
+	return gossh.HostKeyCallback(cb), nil
+}
+
+func sshSigners(remote git.Remote, cfg config.SSHAuth) ([]gossh.Signer, error) {
+	keys := cfg.Keys
+	explicit := len(keys) > 0
+	if !explicit {
+		keys = defaultKeys
+	}
+	signers := make([]gossh.Signer, 0, len(keys))
+	for _, key := range keys {
+		file, err := homedir.Expand(key)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrSSHKey)
+		}
+		pem, err := os.ReadFile(file)
+		if err != nil {
+			if !explicit && os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("%w: %w", ErrSSHKey, err)
+		}
+		signer, err := parseKey(key, pem, cfg.PassphraseEnv)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("%w: none of %q exist, configure the keys "+
+			"of the %s remote", ErrSSHKey, keys, remote.Name)
+	}
+	return signers, nil
+}
+
+func parseKey(key string, pem []byte, passphraseEnv string) (gossh.Signer, error) { //nolint:ireturn
+	signer, err := gossh.ParsePrivateKey(pem)
+	var missing *gossh.PassphraseMissingError
+	if !errors.As(err, &missing) {
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrSSHKey, key, err)
+		}
+		return signer, nil
+	}
+	passphrase := ""
+	if passphraseEnv != "" {
+		passphrase = os.Getenv(passphraseEnv)
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("%w: %s is encrypted, and the passphrase "+
+			"variable is empty, or not configured", ErrSSHKey, key)
+	}
+	signer, err = gossh.ParsePrivateKeyWithPassphrase(pem, []byte(passphrase))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrSSHKey, key, err)
+	}
+	return signer, nil
+}
+
+// remoteError tells apart the host key verification failures.
+func remoteError(remote git.Remote, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case knownhosts.IsHostKeyChanged(err):
+		return fmt.Errorf("%w: %w: %s presented a key, which differs from "+
+			"the known one, verify the known hosts: %w",
+			ErrRemoteOperationFailed, ErrHostKeyMismatch, remote.URL, err)
+	case knownhosts.IsHostUnknown(err):
+		return fmt.Errorf("%w: %w: %s isn't in the known hosts: %w",
+			ErrRemoteOperationFailed, ErrUnknownHost, remote.URL, err)
+	default:
+		return errors.Wrap(err, ErrRemoteOperationFailed)
+	}
+}
@@ -0,0 +1,142 @@
+package git_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/config"
+	configgit "github.com/openshift-knative/deviate/pkg/config/git"
+	"github.com/openshift-knative/deviate/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestRepository_SSHAuthentication(t *testing.T) {
+	hostKey := newSigner(t)
+	addr := serveSSH(t, hostKey)
+	tcs := []struct {
+		name       string
+		knownHost  gossh.PublicKey
+		passphrase string
+		encrypted  bool
+		wantErr    error
+	}{{
+		name:      "known host",
+		knownHost: hostKey.PublicKey(),
+	}, {
+		name:      "host key mismatch",
+		knownHost: newSigner(t).PublicKey(),
+		wantErr:   git.ErrHostKeyMismatch,
+	}, {
+		name:    "unknown host",
+		wantErr: git.ErrUnknownHost,
+	}, {
+		name:       "encrypted key",
+		knownHost:  hostKey.PublicKey(),
+		encrypted:  true,
+		passphrase: "s3cr3t",
+	}, {
+		name:      "encrypted key without passphrase",
+		knownHost: hostKey.PublicKey(),
+		encrypted: true,
+		wantErr:   git.ErrSSHKey,
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			knownHosts := path.Join(dir, "known_hosts")
+			content := ""
+			if tc.knownHost != nil {
+				content = knownhosts.Line([]string{addr}, tc.knownHost) + "\n"
+			}
+			require.NoError(t, os.WriteFile(knownHosts, []byte(content), 0o600))
+			key := path.Join(dir, "id_ed25519")
+			require.NoError(t, os.WriteFile(key, privateKey(t, tc.encrypted), 0o600))
+			t.Setenv("SSH_KEY_PASSPHRASE", tc.passphrase)
+			repo := &git.Repository{
+				Context: t.Context(),
+				Auth: config.Auth{Downstream: config.RemoteAuth{SSH: config.SSHAuth{
+					User:          "git",
+					Keys:          []string{key},
+					PassphraseEnv: "SSH_KEY_PASSPHRASE",
+					KnownHosts:    knownHosts,
+				}}},
+			}
+
+			_, err := repo.ListRemote(configgit.Remote{
+				Name: "downstream",
+				URL:  fmt.Sprintf("ssh://git@%s/acme/fork.git", addr),
+			})
+
+			// The server only performs the handshake, so listing always fails.
+			require.Error(t, err)
+			for _, e := range []error{git.ErrHostKeyMismatch, git.ErrUnknownHost, git.ErrSSHKey} {
+				assert.Equal(t, errors.Is(tc.wantErr, e), errors.Is(err, e), err)
+			}
+		})
+	}
+}
+
+func newSigner(tb testing.TB) gossh.Signer { //nolint:ireturn
+	tb.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(tb, err)
+	signer, err := gossh.NewSignerFromKey(priv)
+	require.NoError(tb, err)
+	return signer
+}
+
+func privateKey(tb testing.TB, encrypted bool) []byte {
+	tb.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(tb, err)
+	var block *pem.Block
+	if encrypted {
+		block, err = gossh.MarshalPrivateKeyWithPassphrase(priv, "", []byte("s3cr3t"))
+	} else {
+		block, err = gossh.MarshalPrivateKey(priv, "")
+	}
+	require.NoError(tb, err)
+	return pem.EncodeToMemory(block)
+}
+
+// serveSSH accepts SSH connections, authenticating any public key, and
+// closes them right after the handshake.
+func serveSSH(tb testing.TB, hostKey gossh.Signer) string {
+	tb.Helper()
+	cfg := &gossh.ServerConfig{
+		PublicKeyCallback: func(gossh.ConnMetadata, gossh.PublicKey) (*gossh.Permissions, error) {
+			return &gossh.Permissions{}, nil
+		},
+	}
+	cfg.AddHostKey(hostKey)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(tb, err)
+	tb.Cleanup(func() {
+		_ = ln.Close()
+	})
+	go func() {
+		for {
+			conn, aerr := ln.Accept()
+			if aerr != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if sc, _, _, serr := gossh.NewServerConn(conn, cfg); serr == nil {
+					_ = sc.Close()
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
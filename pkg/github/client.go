@@ -2,68 +2,116 @@ package github
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
 
-	"github.com/cli/cli/v2/pkg/cmd/factory"
-	ghroot "github.com/cli/cli/v2/pkg/cmd/root"
 	"github.com/openshift-knative/deviate/pkg/errors"
-	"github.com/openshift-knative/deviate/pkg/files"
-	"github.com/openshift-knative/deviate/pkg/metadata"
+	"github.com/openshift-knative/deviate/pkg/rest"
 )
 
 // ErrClientFailed when client operations has failed.
 var ErrClientFailed = errors.New("client failed")
 
-// NewClient creates new client.
-func NewClient(args ...string) Client {
-	return Client{Args: args}
-}
+// DefaultBaseURL is the address of the public GitHub API.
+const DefaultBaseURL = "https://api.github.com"
 
-// Client a client for Github CLI.
+// Client is a client of the GitHub REST and GraphQL APIs.
 type Client struct {
-	Args         []string
-	DisableColor bool
-	ProjectDir   string
+	// BaseURL of the REST API, like https://api.github.com or
+	// https://github.example.com/api/v3 for GitHub Enterprise.
+	BaseURL string
+	// Token used to authenticate. Requests are anonymous if it's empty.
+	Token      string
+	HTTPClient *http.Client
 }
 
-// Execute a Github client CLI command.
-func (c Client) Execute(ctx context.Context) (bytes []byte, err error) {
-	buildVersion := metadata.Version
-	cmdFactory := factory.New(buildVersion)
-	cmd, gerr := ghroot.NewCmdRoot(cmdFactory, buildVersion, "-")
-	if gerr != nil {
-		err = errors.Join(err, errors.Wrap(gerr, ErrClientFailed))
-		return bytes, err
+// NewClient creates new client configured from the environment. The token is
+// taken from GITHUB_TOKEN, or GH_TOKEN, and the base URL from GITHUB_API_URL.
+func NewClient() *Client {
+	baseURL := os.Getenv("GITHUB_API_URL")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
 	}
-	cmd.SetArgs(c.Args)
-	tmpf, terr := os.CreateTemp("", "gh-")
-	if terr != nil {
-		err = errors.Join(err, errors.Wrap(terr, ErrClientFailed))
-		return bytes, err
+	return &Client{
+		BaseURL:    baseURL,
+		Token:      token,
+		HTTPClient: http.DefaultClient,
 	}
-	defer func() {
-		rmerr := os.Remove(tmpf.Name())
-		err = errors.Join(err, errors.Wrap(rmerr, ErrClientFailed))
-	}()
-	cmdFactory.IOStreams.Out = tmpf
-	cmdFactory.IOStreams.ErrOut = os.Stderr
-	if c.DisableColor {
-		cmdFactory.IOStreams.SetColorEnabled(false)
+}
+
+// do sends the request to the path, relative to the base URL.
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	return c.send(ctx, method, c.url(path), body, out)
+}
+
+func (c *Client) send(ctx context.Context, method, u string, body, out any) error {
+	return errors.Wrap(c.rest().Send(ctx, method, u, body, out), ErrClientFailed)
+}
+
+// list gets the items of all the pages, starting at the path, relative to the
+// base URL.
+func list[T any](ctx context.Context, c *Client, path string, items *[]T) error {
+	return errors.Wrap(rest.SendPaged(ctx, c.rest(), c.url(path), items), ErrClientFailed)
+}
+
+func (c *Client) rest() rest.Client {
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.Token != "" {
+		header.Set("Authorization", "Bearer "+c.Token)
 	}
-	runner := func() error {
-		return errors.Wrap(cmd.ExecuteContext(ctx), ErrClientFailed)
+	return rest.Client{
+		HTTPClient:   c.HTTPClient,
+		Header:       header,
+		ErrorMessage: errorMessage,
 	}
-	if c.ProjectDir != "" {
-		previousRunner := runner
-		runner = func() error {
-			return errors.Wrap(files.WithinDirectory(c.ProjectDir, previousRunner),
-				ErrClientFailed)
+}
+
+func (c *Client) url(path string) string {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	return strings.TrimSuffix(base, "/") + path
+}
+
+func errorMessage(buf []byte) string {
+	var msg struct {
+		Message string `json:"message"`
+		Errors  []struct {
+			Message string `json:"message"`
+			Field   string `json:"field"`
+			Code    string `json:"code"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(buf, &msg); err != nil || msg.Message == "" {
+		return ""
+	}
+	details := make([]string, 0, len(msg.Errors))
+	for _, e := range msg.Errors {
+		switch {
+		case e.Message != "":
+			details = append(details, e.Message)
+		case e.Field != "":
+			details = append(details, e.Field+" "+e.Code)
 		}
 	}
-	err = errors.Join(err, runner())
-	bytes, ferr := os.ReadFile(tmpf.Name())
-	if ferr != nil {
-		err = errors.Join(err, errors.Wrap(ferr, ErrClientFailed))
+	if len(details) > 0 {
+		return msg.Message + ": " + strings.Join(details, "; ")
 	}
-	return bytes, err
+	return msg.Message
+}
+
+// repoPath returns the API path of the repository given as owner/name.
+func repoPath(repo string) string {
+	owner, name, _ := strings.Cut(repo, "/")
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name)
 }
@@ -0,0 +1,212 @@
+package github_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/github"
+	"github.com/openshift-knative/deviate/pkg/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_PullRequests(t *testing.T) {
+	var labels []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/fork/pulls", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer s3cr3t", r.Header.Get("Authorization"))
+		assert.Equal(t, "acme:ci/release-1.2", r.URL.Query().Get("head"))
+		assert.Equal(t, "release-1.2", r.URL.Query().Get("base"))
+		assert.Equal(t, "open", r.URL.Query().Get("state"))
+		writeJSON(t, w, http.StatusOK, []github.PullRequest{{
+			Number: 7,
+			URL:    "https://github.example/acme/fork/pull/7",
+		}})
+	})
+	mux.HandleFunc("POST /repos/acme/fork/pulls", func(w http.ResponseWriter, r *http.Request) {
+		var pr github.NewPullRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&pr))
+		assert.Equal(t, "ci/release-1.2", pr.Head)
+		writeJSON(t, w, http.StatusCreated, github.PullRequest{
+			Number: 8, Title: pr.Title,
+		})
+	})
+	mux.HandleFunc("POST /repos/acme/fork/issues/8/labels", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string][]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		labels = body["labels"]
+		writeJSON(t, w, http.StatusOK, []github.Label{})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	cl := &github.Client{BaseURL: srv.URL, Token: "s3cr3t"}
+	ctx := t.Context()
+
+	prs, err := cl.ListPullRequests(ctx, "acme/fork", github.ListOptions{
+		Head: "ci/release-1.2", Base: "release-1.2",
+	})
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, "https://github.example/acme/fork/pull/7", prs[0].URL)
+
+	pr, err := cl.CreatePullRequest(ctx, "acme/fork", github.NewPullRequest{
+		Title: "Sync", Head: "ci/release-1.2", Base: "release-1.2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 8, pr.Number)
+	require.NoError(t, cl.AddLabels(ctx, "acme/fork", pr.Number, "a", "b"))
+	assert.Equal(t, []string{"a", "b"}, labels)
+}
+
+func TestClient_Routing(t *testing.T) {
+	var reviewers, assignees map[string][]string
+	var milestone map[string]int
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/acme/fork/pulls/8/requested_reviewers", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reviewers))
+		writeJSON(t, w, http.StatusCreated, github.PullRequest{Number: 8})
+	})
+	mux.HandleFunc("POST /repos/acme/fork/issues/8/assignees", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&assignees))
+		writeJSON(t, w, http.StatusCreated, github.PullRequest{Number: 8})
+	})
+	mux.HandleFunc("GET /repos/acme/fork/milestones", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, http.StatusOK, []github.Milestone{
+			{Number: 1, Title: "1.1"}, {Number: 2, Title: "1.2"},
+		})
+	})
+	mux.HandleFunc("PATCH /repos/acme/fork/issues/8", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&milestone))
+		writeJSON(t, w, http.StatusOK, github.PullRequest{Number: 8})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	cl := &github.Client{BaseURL: srv.URL}
+	ctx := t.Context()
+
+	require.NoError(t, cl.RequestReviewers(ctx, "acme/fork", 8, []string{"alice"}, nil))
+	assert.Equal(t, map[string][]string{
+		"reviewers": {"alice"}, "team_reviewers": {},
+	}, reviewers)
+	require.NoError(t, cl.AddAssignees(ctx, "acme/fork", 8, "bob"))
+	assert.Equal(t, []string{"bob"}, assignees["assignees"])
+	require.NoError(t, cl.SetMilestone(ctx, "acme/fork", 8, "1.2"))
+	assert.Equal(t, map[string]int{"milestone": 2}, milestone)
+	require.ErrorIs(t, cl.SetMilestone(ctx, "acme/fork", 8, "2.0"),
+		github.ErrClientFailed)
+}
+
+func TestClient_Pagination(t *testing.T) {
+	var milestone map[string]int
+	mux := http.NewServeMux()
+	// paged serves the pages of the items, linking the next page, like GitHub.
+	paged := func(pages ...any) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			page = max(page, 1)
+			assert.Equal(t, "open", r.URL.Query().Get("state"))
+			if page < len(pages) {
+				q := r.URL.Query()
+				q.Set("page", strconv.Itoa(page+1))
+				next := "http://" + r.Host + r.URL.Path + "?" + q.Encode()
+				q.Set("page", strconv.Itoa(len(pages)))
+				last := "http://" + r.Host + r.URL.Path + "?" + q.Encode()
+				w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next", <%s>; rel="last"`,
+					next, last))
+			}
+			writeJSON(t, w, http.StatusOK, pages[page-1])
+		}
+	}
+	mux.HandleFunc("GET /repos/acme/fork/pulls", paged(
+		[]github.PullRequest{{Number: 1}, {Number: 2}},
+		[]github.PullRequest{{Number: 3}},
+	))
+	mux.HandleFunc("GET /repos/acme/fork/milestones", paged(
+		[]github.Milestone{{Number: 1, Title: "1.1"}},
+		[]github.Milestone{{Number: 2, Title: "1.2"}},
+		[]github.Milestone{{Number: 3, Title: "1.3"}},
+	))
+	mux.HandleFunc("PATCH /repos/acme/fork/issues/8", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&milestone))
+		writeJSON(t, w, http.StatusOK, github.PullRequest{Number: 8})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	cl := &github.Client{BaseURL: srv.URL}
+	ctx := t.Context()
+
+	prs, err := cl.ListPullRequests(ctx, "acme/fork", github.ListOptions{})
+	require.NoError(t, err)
+	numbers := make([]int, 0, len(prs))
+	for _, pr := range prs {
+		numbers = append(numbers, pr.Number)
+	}
+	assert.Equal(t, []int{1, 2, 3}, numbers)
+	require.NoError(t, cl.SetMilestone(ctx, "acme/fork", 8, "1.3"))
+	assert.Equal(t, map[string]int{"milestone": 3}, milestone)
+}
+
+func TestClient_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, http.StatusUnprocessableEntity, map[string]any{
+			"message": "Validation Failed",
+			"errors": []map[string]string{{
+				"message": "A pull request already exists for acme:ci/release-1.2.",
+			}},
+		})
+	}))
+	defer srv.Close()
+	cl := &github.Client{BaseURL: srv.URL}
+
+	_, err := cl.CreatePullRequest(t.Context(), "acme/fork", github.NewPullRequest{})
+
+	require.ErrorIs(t, err, github.ErrClientFailed)
+	var apiErr *rest.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+	assert.Equal(t, "Validation Failed: A pull request already exists "+
+		"for acme:ci/release-1.2.", apiErr.Message)
+}
+
+func TestClient_GraphQL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/graphql", r.URL.Path)
+		var req struct {
+			Variables map[string]any `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.Variables["fail"] == true {
+			writeJSON(t, w, http.StatusOK, map[string]any{
+				"errors": []map[string]string{{"message": "boom"}},
+			})
+			return
+		}
+		writeJSON(t, w, http.StatusOK, map[string]any{
+			"data": map[string]any{"viewer": map[string]string{"login": "bot"}},
+		})
+	}))
+	defer srv.Close()
+	cl := &github.Client{BaseURL: srv.URL + "/api/v3"}
+	var out struct {
+		Viewer struct {
+			Login string `json:"login"`
+		} `json:"viewer"`
+	}
+
+	require.NoError(t, cl.GraphQL(t.Context(), "query { viewer { login } }", nil, &out))
+	assert.Equal(t, "bot", out.Viewer.Login)
+
+	err := cl.GraphQL(t.Context(), "query", map[string]any{"fail": true}, nil)
+	require.ErrorIs(t, err, github.ErrGraphQL)
+}
+
+func writeJSON(tb testing.TB, w http.ResponseWriter, status int, v any) {
+	tb.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	require.NoError(tb, json.NewEncoder(w).Encode(v))
+}
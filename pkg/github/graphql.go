@@ -0,0 +1,69 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/openshift-knative/deviate/pkg/errors"
+)
+
+// ErrGraphQL when the GraphQL API responds with errors.
+var ErrGraphQL = errors.New("GraphQL query failed")
+
+// GraphQL executes the query with given variables, and decodes the data of the
+// response into out.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]any, out any) error {
+	req := map[string]any{"query": query, "variables": variables}
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := c.send(ctx, http.MethodPost, c.graphqlURL(), req, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		msgs := make([]string, 0, len(resp.Errors))
+		for _, e := range resp.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return fmt.Errorf("%w: %w: %s", ErrClientFailed, ErrGraphQL,
+			strings.Join(msgs, "; "))
+	}
+	if out == nil || len(resp.Data) == 0 {
+		return nil
+	}
+	return errors.Wrap(json.Unmarshal(resp.Data, out), ErrClientFailed)
+}
+
+// graphqlURL returns the GraphQL endpoint for the REST base URL. GitHub
+// Enterprise serves REST under /api/v3, and GraphQL under /api/graphql.
+func (c *Client) graphqlURL() string {
+	base := strings.TrimSuffix(c.BaseURL, "/")
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	if strings.HasSuffix(base, "/api/v3") {
+		return strings.TrimSuffix(base, "/v3") + "/graphql"
+	}
+	return base + "/graphql"
+}
+
+const enableAutoMergeMutation = `mutation($id: ID!, $method: PullRequestMergeMethod!) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method}) {
+    clientMutationId
+  }
+}`
+
+// EnableAutoMerge enables auto-merge of the pull request, given by its node ID.
+// The method is one of MERGE, SQUASH or REBASE.
+func (c *Client) EnableAutoMerge(ctx context.Context, nodeID, method string) error {
+	return c.GraphQL(ctx, enableAutoMergeMutation, map[string]any{
+		"id":     nodeID,
+		"method": strings.ToUpper(method),
+	}, nil)
+}
@@ -0,0 +1,170 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PullRequest is a GitHub pull request.
+type PullRequest struct {
+	Number int     `json:"number"`
+	NodeID string  `json:"node_id"`
+	URL    string  `json:"html_url"`
+	State  string  `json:"state"`
+	Title  string  `json:"title"`
+	Body   string  `json:"body"`
+	Draft  bool    `json:"draft"`
+	Head   Branch  `json:"head"`
+	Base   Branch  `json:"base"`
+	Labels []Label `json:"labels"`
+}
+
+// Branch is a head or base of the pull request.
+type Branch struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+// Label of an issue or pull request.
+type Label struct {
+	Name string `json:"name"`
+}
+
+// ListOptions filter the listed pull requests.
+type ListOptions struct {
+	// Head is a branch name, within the repository of the pull request.
+	Head string
+	Base string
+	// State is either open, closed or all. Defaults to open.
+	State string
+}
+
+// NewPullRequest holds the data of the pull request to create.
+type NewPullRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Head  string `json:"head"`
+	Base  string `json:"base"`
+	Draft bool   `json:"draft,omitempty"`
+}
+
+// PullRequestUpdate holds the changes of the pull request. Nil fields are left
+// unchanged.
+type PullRequestUpdate struct {
+	Title *string `json:"title,omitempty"`
+	Body  *string `json:"body,omitempty"`
+	State *string `json:"state,omitempty"`
+	Base  *string `json:"base,omitempty"`
+}
+
+// ListPullRequests lists pull requests of the repository, given as owner/name.
+// All the pages of the list are fetched.
+func (c *Client) ListPullRequests(ctx context.Context, repo string, opts ListOptions) ([]PullRequest, error) {
+	q := url.Values{}
+	state := opts.State
+	if state == "" {
+		state = "open"
+	}
+	q.Set("state", state)
+	if opts.Head != "" {
+		owner, _, _ := strings.Cut(repo, "/")
+		q.Set("head", owner+":"+opts.Head)
+	}
+	if opts.Base != "" {
+		q.Set("base", opts.Base)
+	}
+	q.Set("per_page", "100")
+	prs := make([]PullRequest, 0)
+	err := list(ctx, c, repoPath(repo)+"/pulls?"+q.Encode(), &prs)
+	return prs, err
+}
+
+// CreatePullRequest opens a new pull request.
+func (c *Client) CreatePullRequest(ctx context.Context, repo string, pr NewPullRequest) (*PullRequest, error) {
+	created := &PullRequest{}
+	err := c.do(ctx, http.MethodPost, repoPath(repo)+"/pulls", pr, created)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdatePullRequest changes the pull request.
+func (c *Client) UpdatePullRequest(
+	ctx context.Context, repo string, number int, update PullRequestUpdate,
+) (*PullRequest, error) {
+	updated := &PullRequest{}
+	path := fmt.Sprintf("%s/pulls/%d", repoPath(repo), number)
+	if err := c.do(ctx, http.MethodPatch, path, update, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// AddLabels adds labels to the issue or pull request.
+func (c *Client) AddLabels(ctx context.Context, repo string, number int, labels ...string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	path := fmt.Sprintf("%s/issues/%d/labels", repoPath(repo), number)
+	body := map[string][]string{"labels": labels}
+	return c.do(ctx, http.MethodPost, path, body, nil)
+}
+
+// CreateComment comments on the issue or pull request.
+func (c *Client) CreateComment(ctx context.Context, repo string, number int, body string) error {
+	path := fmt.Sprintf("%s/issues/%d/comments", repoPath(repo), number)
+	return c.do(ctx, http.MethodPost, path, map[string]string{"body": body}, nil)
+}
+
+// Milestone of issues and pull requests.
+type Milestone struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+}
+
+// RequestReviewers requests reviews of the pull request from users and teams.
+func (c *Client) RequestReviewers(ctx context.Context, repo string, number int, users, teams []string) error {
+	if len(users) == 0 && len(teams) == 0 {
+		return nil
+	}
+	path := fmt.Sprintf("%s/pulls/%d/requested_reviewers", repoPath(repo), number)
+	body := map[string][]string{"reviewers": nonNil(users), "team_reviewers": nonNil(teams)}
+	return c.do(ctx, http.MethodPost, path, body, nil)
+}
+
+// AddAssignees assigns users to the issue or pull request.
+func (c *Client) AddAssignees(ctx context.Context, repo string, number int, assignees ...string) error {
+	if len(assignees) == 0 {
+		return nil
+	}
+	path := fmt.Sprintf("%s/issues/%d/assignees", repoPath(repo), number)
+	return c.do(ctx, http.MethodPost, path, map[string][]string{"assignees": assignees}, nil)
+}
+
+// SetMilestone sets the open milestone with given title on the issue, or pull
+// request.
+func (c *Client) SetMilestone(ctx context.Context, repo string, number int, title string) error {
+	milestones := make([]Milestone, 0)
+	err := list(ctx, c, repoPath(repo)+"/milestones?state=open&per_page=100", &milestones)
+	if err != nil {
+		return err
+	}
+	for _, m := range milestones {
+		if m.Title == title {
+			path := fmt.Sprintf("%s/issues/%d", repoPath(repo), number)
+			return c.do(ctx, http.MethodPatch, path, map[string]int{"milestone": m.Number}, nil)
+		}
+	}
+	return fmt.Errorf("%w: no open milestone %q in %s", ErrClientFailed, title, repo)
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,143 @@
+package patch
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/openshift-knative/deviate/pkg/errors"
+)
+
+// ErrPatchDoesNotApply when a patch can't be applied onto the content.
+var ErrPatchDoesNotApply = errors.New("patch does not apply")
+
+// DefaultFuzz is a maximal number of context lines, that might be ignored,
+// at the beginning and the end of the hunk, when applying it.
+const DefaultFuzz = 2
+
+// Options controls how patches are applied.
+type Options struct {
+	// Fuzz is a maximal number of context lines, that might be ignored.
+	Fuzz int
+}
+
+// Apply applies the file diff onto the content. Hunks are looked up at the
+// position given in the hunk header first, and then, at increasing offsets
+// from that position. If that fails, up to Options.Fuzz context lines are
+// ignored. All the hunks that can't be applied are reported as HunkError.
+func Apply(content string, fd *FileDiff, opts Options) (string, error) {
+	lines := splitLines(content)
+	result := make([]string, 0, len(lines))
+	var errs []error
+	pos := 0
+	offset := 0
+	for i := range fd.Hunks {
+		h := &fd.Hunks[i]
+		at, fuzz, found := locate(lines, h, pos, offset, opts.Fuzz)
+		if !found {
+			errs = append(errs, &HunkError{
+				File:      fd.Name(),
+				Hunk:      i + 1,
+				Line:      h.OldStart,
+				PatchLine: h.Line,
+				Reason:    "does not apply",
+			})
+			continue
+		}
+		pre, post := h.preimage(), h.postimage()
+		// Fuzzed context lines are kept as they are in the file.
+		pre = pre[fuzz.leading : len(pre)-fuzz.trailing]
+		post = post[fuzz.leading : len(post)-fuzz.trailing]
+		result = append(result, lines[pos:at]...)
+		result = append(result, post...)
+		pos = at + len(pre)
+		offset = at - fuzz.leading - expected(h)
+	}
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+	result = append(result, lines[pos:]...)
+	return strings.Join(result, ""), nil
+}
+
+type fuzzed struct {
+	leading, trailing int
+}
+
+func expected(h *Hunk) int {
+	if h.OldLines == 0 {
+		return h.OldStart
+	}
+	return h.OldStart - 1
+}
+
+func locate(lines []string, h *Hunk, minPos, offset, maxFuzz int) (int, fuzzed, bool) {
+	pre := h.preimage()
+	leading, trailing := contextLines(h)
+	for fuzz := 0; fuzz <= maxFuzz; fuzz++ {
+		f := fuzzed{leading: min(fuzz, leading), trailing: min(fuzz, trailing)}
+		if fuzz > 0 && f.leading+f.trailing == 0 {
+			break
+		}
+		if f.leading+f.trailing >= len(pre) && len(pre) > 0 {
+			break
+		}
+		want := pre[f.leading : len(pre)-f.trailing]
+		start := expected(h) + offset + f.leading
+		if at, ok := search(lines, want, start, minPos); ok {
+			return at, f, true
+		}
+	}
+	return 0, fuzzed{}, false
+}
+
+// search looks for the want lines starting at the given position, and then
+// moving away from it in both directions.
+func search(lines, want []string, start, minPos int) (int, bool) {
+	maxPos := len(lines) - len(want)
+	start = max(min(start, maxPos), minPos)
+	for delta := 0; ; delta++ {
+		before, after := start-delta, start+delta
+		if before < minPos && after > maxPos {
+			return 0, false
+		}
+		if after <= maxPos && matches(lines, want, after) {
+			return after, true
+		}
+		if delta > 0 && before >= minPos && matches(lines, want, before) {
+			return before, true
+		}
+	}
+}
+
+func matches(lines, want []string, at int) bool {
+	return slices.Equal(lines[at:at+len(want)], want)
+}
+
+func contextLines(h *Hunk) (int, int) {
+	leading := 0
+	for _, l := range h.Lines {
+		if l.Op != OpContext {
+			break
+		}
+		leading++
+	}
+	trailing := 0
+	for i := len(h.Lines) - 1; i >= 0 && h.Lines[i].Op == OpContext; i-- {
+		trailing++
+	}
+	if leading == len(h.Lines) {
+		trailing = 0
+	}
+	return leading, trailing
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,101 @@
+package patch_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const examplePatch = `From 1234 Mon Sep 17 00:00:00 2001
+Subject: [PATCH] Example
+
+---
+ a.txt | 2 +-
+ 1 file changed, 1 insertion(+), 1 deletion(-)
+
+diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -2,3 +2,3 @@
+ two
+-three
++THREE
+ four
+diff --git a/new.txt b/new.txt
+new file mode 100755
+index 0000000..3333333
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1 @@
++new
+\ No newline at end of file
+-- 
+2.43.0
+`
+
+func TestParse(t *testing.T) {
+	diffs, err := patch.Parse(strings.NewReader(examplePatch))
+	require.NoError(t, err)
+	require.Len(t, diffs, 2)
+
+	assert.Equal(t, "a.txt", diffs[0].Name())
+	assert.Equal(t, "1111111", diffs[0].OldHash)
+	require.Len(t, diffs[0].Hunks, 1)
+	assert.Len(t, diffs[0].Hunks[0].Lines, 4)
+
+	assert.Equal(t, "new.txt", diffs[1].Name())
+	assert.True(t, diffs[1].IsNew)
+	assert.Equal(t, uint32(0o100755), diffs[1].NewMode)
+	require.Len(t, diffs[1].Hunks, 1)
+	assert.Equal(t, "new", diffs[1].Hunks[0].Lines[0].Text)
+}
+
+func TestApply(t *testing.T) {
+	diffs, err := patch.Parse(strings.NewReader(examplePatch))
+	require.NoError(t, err)
+	fd := diffs[0]
+	opts := patch.Options{Fuzz: patch.DefaultFuzz}
+	tcs := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{{
+		name:    "exact",
+		content: "one\ntwo\nthree\nfour\nfive\n",
+		want:    "one\ntwo\nTHREE\nfour\nfive\n",
+	}, {
+		name:    "offset",
+		content: "zero\nhalf\none\ntwo\nthree\nfour\nfive\n",
+		want:    "zero\nhalf\none\ntwo\nTHREE\nfour\nfive\n",
+	}, {
+		name:    "fuzz",
+		content: "one\n2\nthree\n4\nfive\n",
+		want:    "one\n2\nTHREE\n4\nfive\n",
+	}, {
+		name:    "conflict",
+		content: "one\ntwo\n3\nfour\nfive\n",
+		wantErr: true,
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := patch.Apply(tc.content, fd, opts)
+			if tc.wantErr {
+				var herr *patch.HunkError
+				require.ErrorAs(t, err, &herr)
+				assert.Equal(t, "a.txt", herr.File)
+				assert.Equal(t, 2, herr.Line)
+				assert.Equal(t, 1, herr.Hunk)
+				assert.True(t, errors.Is(err, patch.ErrPatchDoesNotApply))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
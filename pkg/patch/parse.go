@@ -0,0 +1,243 @@
+package patch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/openshift-knative/deviate/pkg/errors"
+)
+
+var (
+	// ErrInvalidPatch when the patch can't be parsed.
+	ErrInvalidPatch = errors.New("invalid patch")
+
+	hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+	indexRe      = regexp.MustCompile(`^index ([0-9a-f]+)\.\.([0-9a-f]+)(?: (\d+))?$`)
+)
+
+const devNull = "/dev/null"
+
+// Parse reads a unified diff, as produced by git diff or git format-patch.
+func Parse(r io.Reader) ([]*FileDiff, error) {
+	p := parser{scanner: bufio.NewScanner(r)}
+	const maxLine = 16 * 1024 * 1024
+	p.scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLine)
+	return p.parse()
+}
+
+type parser struct {
+	scanner *bufio.Scanner
+	line    string
+	lineNo  int
+	peeked  bool
+	eof     bool
+}
+
+func (p *parser) next() bool {
+	if p.peeked {
+		p.peeked = false
+		return true
+	}
+	if p.eof || !p.scanner.Scan() {
+		p.eof = true
+		return false
+	}
+	p.line = p.scanner.Text()
+	p.lineNo++
+	return true
+}
+
+func (p *parser) unread() {
+	p.peeked = true
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: line %d: %s", ErrInvalidPatch, p.lineNo,
+		fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parse() ([]*FileDiff, error) {
+	diffs := make([]*FileDiff, 0)
+	var current *FileDiff
+	for p.next() {
+		switch {
+		case strings.HasPrefix(p.line, "diff --git "):
+			current = &FileDiff{}
+			current.OldName, current.NewName = parseGitHeader(p.line)
+			diffs = append(diffs, current)
+		case strings.HasPrefix(p.line, "--- "):
+			if current == nil || len(current.Hunks) > 0 || current.oldSeen {
+				current = &FileDiff{}
+				diffs = append(diffs, current)
+			}
+			current.oldSeen = true
+			current.OldName = parseFileName(p.line[4:])
+			if current.OldName == "" {
+				current.IsNew = true
+			}
+		case strings.HasPrefix(p.line, "+++ ") && current != nil:
+			current.NewName = parseFileName(p.line[4:])
+			if current.NewName == "" {
+				current.IsDelete = true
+			}
+		case strings.HasPrefix(p.line, "@@ ") && current != nil:
+			h, err := p.parseHunk()
+			if err != nil {
+				return nil, err
+			}
+			current.Hunks = append(current.Hunks, h)
+		case current != nil && len(current.Hunks) == 0:
+			if err := p.parseExtendedHeader(current); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := p.scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, ErrInvalidPatch)
+	}
+	return diffs, nil
+}
+
+func (p *parser) parseExtendedHeader(fd *FileDiff) error {
+	line := p.line
+	switch {
+	case strings.HasPrefix(line, "new file mode "):
+		fd.IsNew = true
+		return p.parseMode(line, "new file mode ", &fd.NewMode)
+	case strings.HasPrefix(line, "deleted file mode "):
+		fd.IsDelete = true
+		return p.parseMode(line, "deleted file mode ", &fd.OldMode)
+	case strings.HasPrefix(line, "old mode "):
+		return p.parseMode(line, "old mode ", &fd.OldMode)
+	case strings.HasPrefix(line, "new mode "):
+		return p.parseMode(line, "new mode ", &fd.NewMode)
+	case strings.HasPrefix(line, "rename from "):
+		fd.IsRename = true
+		fd.OldName = strings.TrimPrefix(line, "rename from ")
+	case strings.HasPrefix(line, "rename to "):
+		fd.IsRename = true
+		fd.NewName = strings.TrimPrefix(line, "rename to ")
+	case strings.HasPrefix(line, "copy from "):
+		fd.IsCopy = true
+		fd.OldName = strings.TrimPrefix(line, "copy from ")
+	case strings.HasPrefix(line, "copy to "):
+		fd.IsCopy = true
+		fd.NewName = strings.TrimPrefix(line, "copy to ")
+	case strings.HasPrefix(line, "Binary files ") ||
+		strings.HasPrefix(line, "GIT binary patch"):
+		fd.IsBinary = true
+	default:
+		if m := indexRe.FindStringSubmatch(line); m != nil {
+			fd.OldHash, fd.NewHash = m[1], m[2]
+			if m[3] != "" {
+				return p.parseMode(m[3], "", &fd.NewMode)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseMode(line, prefix string, mode *uint32) error {
+	m, err := strconv.ParseUint(strings.TrimPrefix(line, prefix), 8, 32)
+	if err != nil {
+		return p.errorf("invalid file mode: %q", line)
+	}
+	*mode = uint32(m)
+	return nil
+}
+
+func (p *parser) parseHunk() (Hunk, error) {
+	m := hunkHeaderRe.FindStringSubmatch(p.line)
+	if m == nil {
+		return Hunk{}, p.errorf("invalid hunk header: %q", p.line)
+	}
+	h := Hunk{
+		OldStart: atoi(m[1], 0),
+		OldLines: atoi(m[2], 1),
+		NewStart: atoi(m[3], 0),
+		NewLines: atoi(m[4], 1),
+		Line:     p.lineNo,
+	}
+	oldLines, newLines := 0, 0
+	for oldLines < h.OldLines || newLines < h.NewLines {
+		if !p.next() {
+			return Hunk{}, p.errorf("unexpected end of hunk at %s",
+				h.header())
+		}
+		text := p.line
+		if text == "" {
+			// Some editors strip the trailing space of empty context lines.
+			text = " "
+		}
+		op := Op(text[0])
+		switch op {
+		case OpContext:
+			oldLines++
+			newLines++
+		case OpDelete:
+			oldLines++
+		case OpAdd:
+			newLines++
+		default:
+			if strings.HasPrefix(text, `\`) {
+				h.noNewline()
+				continue
+			}
+			return Hunk{}, p.errorf("invalid line in hunk %s: %q",
+				h.header(), p.line)
+		}
+		h.Lines = append(h.Lines, Line{Op: op, Text: text[1:] + "\n"})
+	}
+	if p.next() {
+		if strings.HasPrefix(p.line, `\`) {
+			h.noNewline()
+		} else {
+			p.unread()
+		}
+	}
+	return h, nil
+}
+
+func parseGitHeader(line string) (string, string) {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if idx := strings.Index(rest, " b/"); idx >= 0 {
+		return stripPrefix(rest[:idx]), stripPrefix(rest[idx+1:])
+	}
+	return "", ""
+}
+
+func parseFileName(name string) string {
+	if idx := strings.IndexByte(name, '\t'); idx >= 0 {
+		name = name[:idx]
+	}
+	name = strings.TrimSpace(name)
+	if name == devNull {
+		return ""
+	}
+	if unquoted, err := strconv.Unquote(name); err == nil {
+		name = unquoted
+	}
+	return stripPrefix(name)
+}
+
+func stripPrefix(name string) string {
+	if strings.HasPrefix(name, "a/") || strings.HasPrefix(name, "b/") {
+		return name[2:]
+	}
+	return name
+}
+
+func atoi(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+	return i
+}
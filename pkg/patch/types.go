@@ -0,0 +1,114 @@
+package patch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FileDiff is a set of changes to a single file.
+type FileDiff struct {
+	OldName  string
+	NewName  string
+	OldMode  uint32
+	NewMode  uint32
+	OldHash  string
+	NewHash  string
+	IsNew    bool
+	IsDelete bool
+	IsRename bool
+	IsCopy   bool
+	IsBinary bool
+	Hunks    []Hunk
+
+	oldSeen bool
+}
+
+// Name returns the name of the file the diff operates on.
+func (f FileDiff) Name() string {
+	if f.NewName != "" {
+		return f.NewName
+	}
+	return f.OldName
+}
+
+// Hunk is a single change region of a file.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []Line
+	// Line is a line number of the hunk header in the patch file.
+	Line int
+}
+
+// Op is an operation of a line within the hunk.
+type Op byte
+
+const (
+	// OpContext is an unchanged line.
+	OpContext Op = ' '
+	// OpDelete is a removed line.
+	OpDelete Op = '-'
+	// OpAdd is an added line.
+	OpAdd Op = '+'
+)
+
+// Line of a hunk, including the trailing newline, if present.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+func (h *Hunk) header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@",
+		h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+}
+
+func (h *Hunk) noNewline() {
+	if len(h.Lines) > 0 {
+		last := &h.Lines[len(h.Lines)-1]
+		last.Text = strings.TrimSuffix(last.Text, "\n")
+	}
+}
+
+// preimage returns lines the hunk expects to find in the file.
+func (h *Hunk) preimage() []string {
+	return h.lines(OpDelete)
+}
+
+// postimage returns lines the hunk will leave in the file.
+func (h *Hunk) postimage() []string {
+	return h.lines(OpAdd)
+}
+
+func (h *Hunk) lines(op Op) []string {
+	result := make([]string, 0, len(h.Lines))
+	for _, l := range h.Lines {
+		if l.Op == OpContext || l.Op == op {
+			result = append(result, l.Text)
+		}
+	}
+	return result
+}
+
+// HunkError is returned when a hunk can't be applied.
+type HunkError struct {
+	File string
+	// Hunk is a 1-based index of the hunk within the file diff.
+	Hunk int
+	// Line is a line number in the target file, the hunk was expected at.
+	Line int
+	// PatchLine is a line number of the hunk header in the patch.
+	PatchLine int
+	Reason    string
+}
+
+func (e *HunkError) Error() string {
+	return fmt.Sprintf("%s:%d: hunk #%d (patch line %d) %s",
+		e.File, e.Line, e.Hunk, e.PatchLine, e.Reason)
+}
+
+func (e *HunkError) Unwrap() error {
+	return ErrPatchDoesNotApply
+}
@@ -0,0 +1,99 @@
+// Package provenance records the origin of the commits made by deviate as
+// git trailers, and reads them back.
+package provenance
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"time"
+)
+
+// Trailer keys of the provenance.
+const (
+	VersionKey        = "Deviate-Version"
+	UpstreamCommitKey = "Upstream-Commit"
+	UpstreamRefKey    = "Upstream-Ref"
+	StepKey           = "Deviate-Step"
+	RunIDKey          = "Deviate-Run-Id"
+)
+
+// RunIDEnv is the variable that overrides the generated run ID, so the
+// commits can be correlated with the CI job that made them.
+const RunIDEnv = "DEVIATE_RUN_ID"
+
+// Provenance of a commit made by deviate.
+type Provenance struct {
+	// Version of deviate.
+	Version string
+	// UpstreamCommit is a hash of the upstream commit the change is based on.
+	UpstreamCommit string
+	// UpstreamRef is the upstream branch the change is based on.
+	UpstreamRef string
+	// Step of the sync, that made the commit.
+	Step string
+	// RunID identifies the deviate run.
+	RunID string
+}
+
+// Trailers returns the provenance as trailers, skipping the empty ones.
+func (p Provenance) Trailers() []Trailer {
+	all := []Trailer{
+		{Key: VersionKey, Value: p.Version},
+		{Key: UpstreamRefKey, Value: p.UpstreamRef},
+		{Key: UpstreamCommitKey, Value: p.UpstreamCommit},
+		{Key: StepKey, Value: p.Step},
+		{Key: RunIDKey, Value: p.RunID},
+	}
+	trailers := make([]Trailer, 0, len(all))
+	for _, t := range all {
+		if t.Value != "" {
+			trailers = append(trailers, t)
+		}
+	}
+	return trailers
+}
+
+// Append appends the provenance trailers to the commit message.
+func (p Provenance) Append(message string) string {
+	return AppendTrailers(message, p.Trailers()...)
+}
+
+// Parse reads the provenance from the trailers of the commit message. False
+// is returned, if the commit wasn't made by deviate.
+func Parse(message string) (Provenance, bool) {
+	p := Provenance{}
+	found := false
+	for _, t := range ParseTrailers(message) {
+		var field *string
+		switch t.Key {
+		case VersionKey:
+			field = &p.Version
+		case UpstreamCommitKey:
+			field = &p.UpstreamCommit
+		case UpstreamRefKey:
+			field = &p.UpstreamRef
+		case StepKey:
+			field = &p.Step
+		case RunIDKey:
+			field = &p.RunID
+		default:
+			continue
+		}
+		*field = t.Value
+		found = true
+	}
+	return p, found
+}
+
+// NewRunID returns the run ID from the DEVIATE_RUN_ID variable, or generates
+// a new one out of the current time.
+func NewRunID() string {
+	if id := os.Getenv(RunIDEnv); id != "" {
+		return id
+	}
+	const randomBytes = 3
+	b := make([]byte, randomBytes)
+	_, _ = rand.Read(b)
+	return time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b)
+}
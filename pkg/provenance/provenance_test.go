@@ -0,0 +1,87 @@
+package provenance_test
+
+import (
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/provenance"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvenance_RoundTrip(t *testing.T) {
+	p := provenance.Provenance{
+		Version:        "v0.9.0",
+		UpstreamCommit: "4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+		UpstreamRef:    "refs/heads/main",
+		Step:           "fork-files",
+		RunID:          "20261018T120000Z-abcdef",
+	}
+	message := p.Append(":open_file_folder: Apply fork specific files")
+
+	assert.Equal(t, ":open_file_folder: Apply fork specific files\n\n"+
+		"Deviate-Version: v0.9.0\n"+
+		"Upstream-Ref: refs/heads/main\n"+
+		"Upstream-Commit: 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n"+
+		"Deviate-Step: fork-files\n"+
+		"Deviate-Run-Id: 20261018T120000Z-abcdef", message)
+	parsed, ok := provenance.Parse(message)
+	assert.True(t, ok)
+	assert.Equal(t, p, parsed)
+}
+
+func TestProvenance_SkipsEmpty(t *testing.T) {
+	p := provenance.Provenance{Version: "v0.9.0", Step: "merge"}
+	message := p.Append("Merge upstream/main into release-next")
+
+	assert.Equal(t, "Merge upstream/main into release-next\n\n"+
+		"Deviate-Version: v0.9.0\nDeviate-Step: merge", message)
+}
+
+func TestAppendTrailers_ExistingBlock(t *testing.T) {
+	message := "Fix the build\n\nSome details.\n\nSigned-off-by: Jane <jane@example.org>\n"
+	got := provenance.AppendTrailers(message,
+		provenance.Trailer{Key: "Signed-off-by", Value: "Jane <jane@example.org>"},
+		provenance.Trailer{Key: provenance.StepKey, Value: "patches"},
+	)
+
+	assert.Equal(t, "Fix the build\n\nSome details.\n\n"+
+		"Signed-off-by: Jane <jane@example.org>\n"+
+		"Deviate-Step: patches", got)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    provenance.Provenance
+		found   bool
+	}{{
+		name:    "subject only",
+		message: "Deviate-Step: patches",
+	}, {
+		name:    "not a trailer block",
+		message: "Fix the build\n\nDeviate-Step: patches\nas described in the issue",
+	}, {
+		name:    "foreign trailers",
+		message: "Fix the build\n\nSigned-off-by: Jane <jane@example.org>",
+	}, {
+		name:    "mixed trailers",
+		message: "Fix the build\n\nSigned-off-by: Jane <jane@example.org>\nDeviate-Step: patches\n",
+		want:    provenance.Provenance{Step: "patches"},
+		found:   true,
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, found := provenance.Parse(tc.message)
+			assert.Equal(t, tc.found, found)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNewRunID(t *testing.T) {
+	t.Setenv(provenance.RunIDEnv, "")
+	assert.NotEqual(t, provenance.NewRunID(), provenance.NewRunID())
+
+	t.Setenv(provenance.RunIDEnv, "prow-1234")
+	assert.Equal(t, "prow-1234", provenance.NewRunID())
+}
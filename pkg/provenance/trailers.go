@@ -0,0 +1,65 @@
+package provenance
+
+import "strings"
+
+// Trailer is a key-value line, in the last paragraph of a commit message.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+func (t Trailer) String() string {
+	return t.Key + ": " + t.Value
+}
+
+// AppendTrailers appends the trailers to the trailer block of the message,
+// or as a new trailer block, if the message has none. Trailers already
+// present in the message aren't repeated.
+func AppendTrailers(message string, trailers ...Trailer) string {
+	message = strings.TrimRight(message, "\n")
+	existing := ParseTrailers(message)
+	lines := make([]string, 0, len(trailers))
+	for _, t := range trailers {
+		if !contains(existing, t) {
+			lines = append(lines, t.String())
+			existing = append(existing, t)
+		}
+	}
+	if len(lines) == 0 {
+		return message
+	}
+	sep := "\n\n"
+	if len(ParseTrailers(message)) > 0 {
+		sep = "\n"
+	}
+	return message + sep + strings.Join(lines, "\n")
+}
+
+// ParseTrailers returns the trailers of the message. The last paragraph of
+// the message is the trailer block, if all its lines are trailers, and it
+// isn't the subject.
+func ParseTrailers(message string) []Trailer {
+	paragraphs := strings.Split(strings.TrimRight(message, "\n"), "\n\n")
+	if len(paragraphs) < 2 { //nolint:mnd
+		return nil
+	}
+	lines := strings.Split(paragraphs[len(paragraphs)-1], "\n")
+	trailers := make([]Trailer, 0, len(lines))
+	for _, line := range lines {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: key, Value: value})
+	}
+	return trailers
+}
+
+func contains(trailers []Trailer, t Trailer) bool {
+	for _, e := range trailers {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/metadata"
+)
+
+// ErrRequestFailed when the HTTP request has failed.
+var ErrRequestFailed = errors.New("request failed")
+
+// Client sends JSON requests to a REST API.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Header is added to each request, ex.: to authenticate it.
+	Header http.Header
+	// ErrorMessage extracts a message from the body of unsuccessful response.
+	// The trimmed body is used, if not set.
+	ErrorMessage func(body []byte) string
+}
+
+// APIError is returned when the API responds with an unsuccessful status.
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%v: %s %s: %d %s", ErrRequestFailed, e.Method, e.URL,
+		e.StatusCode, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	return ErrRequestFailed
+}
+
+// Do sends the request to the path, relative to the base URL, with JSON
+// encoded body, if given, and decodes the response into out, if given.
+func (c Client) Do(ctx context.Context, method, path string, body, out any) error {
+	return c.Send(ctx, method, strings.TrimSuffix(c.BaseURL, "/")+path, body, out)
+}
+
+// Send is like Do, but sends the request to the absolute URL.
+func (c Client) Send(ctx context.Context, method, url string, body, out any) error {
+	_, err := c.send(ctx, method, url, body, out)
+	return err
+}
+
+// SendPaged gets the list from the absolute URL, and from the following
+// pages, as linked by the Link header of the responses, appending the items
+// of each page to the list.
+func SendPaged[T any](ctx context.Context, c Client, url string, list *[]T) error {
+	for url != "" {
+		page := make([]T, 0)
+		header, err := c.send(ctx, http.MethodGet, url, nil, &page)
+		if err != nil {
+			return err
+		}
+		*list = append(*list, page...)
+		url = nextPage(header)
+	}
+	return nil
+}
+
+// nextPage returns the URL of the next page, from the Link header, or empty,
+// if it's the last page.
+func nextPage(header http.Header) string {
+	for _, links := range header.Values("Link") {
+		for _, link := range strings.Split(links, ",") {
+			target, params, _ := strings.Cut(link, ";")
+			for _, param := range strings.Split(params, ";") {
+				key, value, _ := strings.Cut(param, "=")
+				if strings.TrimSpace(key) == "rel" &&
+					strings.Trim(strings.TrimSpace(value), `"`) == "next" {
+					return strings.Trim(strings.TrimSpace(target), "<>")
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func (c Client) send(ctx context.Context, method, url string, body, out any) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrRequestFailed)
+		}
+		reader = bytes.NewReader(buf)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrRequestFailed)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", metadata.Name)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range c.Header {
+		req.Header[k] = v
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrRequestFailed)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, &APIError{
+			Method:     method,
+			URL:        url,
+			StatusCode: resp.StatusCode,
+			Message:    c.errorMessage(resp.Body),
+		}
+	}
+	if out == nil {
+		return resp.Header, nil
+	}
+	return resp.Header, errors.Wrap(json.NewDecoder(resp.Body).Decode(out), ErrRequestFailed)
+}
+
+func (c Client) errorMessage(body io.Reader) string {
+	const maxBody = 64 * 1024
+	buf, _ := io.ReadAll(io.LimitReader(body, maxBody))
+	if c.ErrorMessage != nil {
+		if msg := c.ErrorMessage(buf); msg != "" {
+			return msg
+		}
+	}
+	return strings.TrimSpace(string(buf))
+}
@@ -0,0 +1,91 @@
+package semver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openshift-knative/deviate/pkg/errors"
+)
+
+// ErrInvalidRange when the version range can't be parsed.
+var ErrInvalidRange = errors.New("invalid version range")
+
+// Range is a set of versions, given as comparisons, separated by spaces or
+// commas, that all need to match, like ">=1.12 <1.14". A version without an
+// operator, or with "=", matches the same release, so 1.12 matches 1.12,
+// and 1.12.3 as well.
+type Range struct {
+	constraints []constraint
+}
+
+type constraint struct {
+	op      string
+	version Version
+}
+
+// operators are ordered, so the longer ones are matched first.
+var operators = []string{">=", "<=", ">", "<", "="} //nolint:gochecknoglobals
+
+// ParseRange parses the version range, like <1.12, or >=1.12 <1.14.
+func ParseRange(s string) (Range, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+	if len(fields) == 0 {
+		return Range{}, fmt.Errorf("%w: %q", ErrInvalidRange, s)
+	}
+	r := Range{constraints: make([]constraint, 0, len(fields))}
+	for _, field := range fields {
+		c := constraint{op: "="}
+		for _, op := range operators {
+			if rest, ok := strings.CutPrefix(field, op); ok {
+				c.op, field = op, rest
+				break
+			}
+		}
+		v, err := Parse(field)
+		if err != nil {
+			return Range{}, fmt.Errorf("%w: %q: %w", ErrInvalidRange, s, err)
+		}
+		c.version = v
+		r.constraints = append(r.constraints, c)
+	}
+	return r, nil
+}
+
+// Contains tells if the version is within the range.
+func (r Range) Contains(v Version) bool {
+	for _, c := range r.constraints {
+		if !c.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c constraint) matches(v Version) bool {
+	switch c.op {
+	case ">=":
+		return v.Compare(c.version) >= 0
+	case "<=":
+		return v.Compare(c.version) <= 0
+	case ">":
+		return v.Compare(c.version) > 0
+	case "<":
+		return v.Compare(c.version) < 0
+	default:
+		return SameRelease(c.version, v)
+	}
+}
+
+// SameRelease tells if the version is the release of the key. The patch and
+// pre-release are compared only if the key specifies them.
+func SameRelease(key, v Version) bool {
+	if key.Major != v.Major || key.Minor != v.Minor {
+		return false
+	}
+	if key.HasPatch && (!v.HasPatch || key.Patch != v.Patch) {
+		return false
+	}
+	return key.Pre == "" || key.Pre == v.Pre
+}
@@ -0,0 +1,69 @@
+package semver_test
+
+import (
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRange_Contains(t *testing.T) {
+	tcs := []struct {
+		in       string
+		contains []string
+		excludes []string
+	}{{
+		in:       "<1.12",
+		contains: []string{"1.11", "1.11.9", "0.26"},
+		excludes: []string{"1.12", "1.12.1", "1.13"},
+	}, {
+		in:       ">=1.14",
+		contains: []string{"1.14", "1.14.1", "2.0"},
+		excludes: []string{"1.13", "1.14.0-rc1"},
+	}, {
+		in:       ">=1.12 <1.14",
+		contains: []string{"1.12", "1.13.2"},
+		excludes: []string{"1.11", "1.14"},
+	}, {
+		in:       ">1.12,<=1.13",
+		contains: []string{"1.13"},
+		excludes: []string{"1.12", "1.14"},
+	}, {
+		in:       "1.12",
+		contains: []string{"1.12", "1.12.3"},
+		excludes: []string{"1.11", "1.13"},
+	}, {
+		in:       "=1.12.3",
+		contains: []string{"1.12.3"},
+		excludes: []string{"1.12", "1.12.2"},
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.in, func(t *testing.T) {
+			r, err := semver.ParseRange(tc.in)
+			require.NoError(t, err)
+			for _, v := range tc.contains {
+				assert.True(t, r.Contains(mustParse(t, v)), v)
+			}
+			for _, v := range tc.excludes {
+				assert.False(t, r.Contains(mustParse(t, v)), v)
+			}
+		})
+	}
+}
+
+func TestParseRange_Invalid(t *testing.T) {
+	for _, in := range []string{"", " , ", "next", ">=1.x", "~1.2"} {
+		t.Run(in, func(t *testing.T) {
+			_, err := semver.ParseRange(in)
+			require.ErrorIs(t, err, semver.ErrInvalidRange)
+		})
+	}
+}
+
+func mustParse(tb testing.TB, s string) semver.Version {
+	tb.Helper()
+	v, err := semver.Parse(s)
+	require.NoError(tb, err)
+	return v
+}
@@ -0,0 +1,147 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/openshift-knative/deviate/pkg/errors"
+)
+
+// ErrInvalidVersion when the version can't be parsed.
+var ErrInvalidVersion = errors.New("invalid version")
+
+var versionRe = regexp.MustCompile(
+	`^v?(\d+)\.(\d+)(?:\.(\d+))?(?:[-.]?([0-9A-Za-z][0-9A-Za-z.-]*))?$`)
+
+// Version is a semantic version, as used to name releases. The patch number
+// is optional, as most projects cut release branches per minor version.
+type Version struct {
+	Major, Minor, Patch int
+	// Pre is a pre-release identifier, like rc1 or alpha.2.
+	Pre string
+	// HasPatch tells if the patch number is a part of the version.
+	HasPatch bool
+}
+
+// Parse parses the version, like 1.2, v1.2.3, or 1.2-rc1.
+func Parse(s string) (Version, error) {
+	m := versionRe.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+	}
+	v := Version{Major: atoi(m[1]), Minor: atoi(m[2]), Pre: m[4]}
+	if m[3] != "" {
+		v.Patch = atoi(m[3])
+		v.HasPatch = true
+	}
+	return v, nil
+}
+
+// New creates a version out of its textual parts, as captured from a branch
+// name. Empty patch is omitted from the version.
+func New(major, minor, patch, pre string) (Version, error) {
+	v := Version{Pre: strings.TrimLeft(pre, "-.")}
+	var err error
+	if v.Major, err = strconv.Atoi(major); err != nil {
+		return Version{}, fmt.Errorf("%w: major %q", ErrInvalidVersion, major)
+	}
+	if v.Minor, err = strconv.Atoi(minor); err != nil {
+		return Version{}, fmt.Errorf("%w: minor %q", ErrInvalidVersion, minor)
+	}
+	if patch != "" {
+		if v.Patch, err = strconv.Atoi(patch); err != nil {
+			return Version{}, fmt.Errorf("%w: patch %q", ErrInvalidVersion, patch)
+		}
+		v.HasPatch = true
+	}
+	return v, nil
+}
+
+func (v Version) String() string {
+	s := strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor)
+	if v.HasPatch {
+		s += "." + strconv.Itoa(v.Patch)
+	}
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+// Compare returns -1, 0, or +1 depending on whether v is lower, equal, or
+// greater than o. The pre-release versions are lower than the associated
+// normal version, and are compared by their dot separated identifiers, as
+// defined by the Semantic Versioning specification.
+func (v Version) Compare(o Version) int {
+	for _, d := range [...]int{
+		v.Major - o.Major,
+		v.Minor - o.Minor,
+		v.Patch - o.Patch,
+	} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	return comparePre(v.Pre, o.Pre)
+}
+
+// Less reports whether v is lower than o.
+func (v Version) Less(o Version) bool {
+	return v.Compare(o) < 0
+}
+
+func comparePre(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := compareIdentifier(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+// compareIdentifier compares numeric identifiers numerically, and others in
+// ASCII order. Numeric identifiers are lower than non-numeric ones.
+func compareIdentifier(a, b string) int {
+	an, aerr := strconv.Atoi(a)
+	bn, berr := strconv.Atoi(b)
+	switch {
+	case aerr == nil && berr == nil:
+		return sign(an - bn)
+	case aerr == nil:
+		return -1
+	case berr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func sign(d int) int {
+	switch {
+	case d < 0:
+		return -1
+	case d > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func atoi(s string) int {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return i
+}
@@ -0,0 +1,82 @@
+package semver_test
+
+import (
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tcs := []struct {
+		in      string
+		want    semver.Version
+		str     string
+		wantErr bool
+	}{{
+		in:   "1.2",
+		want: semver.Version{Major: 1, Minor: 2},
+		str:  "1.2",
+	}, {
+		in:   "v1.2.3",
+		want: semver.Version{Major: 1, Minor: 2, Patch: 3, HasPatch: true},
+		str:  "1.2.3",
+	}, {
+		in:   "v1.2-rc1",
+		want: semver.Version{Major: 1, Minor: 2, Pre: "rc1"},
+		str:  "1.2-rc1",
+	}, {
+		in:   "1.2.0-alpha.2",
+		want: semver.Version{Major: 1, Minor: 2, HasPatch: true, Pre: "alpha.2"},
+		str:  "1.2.0-alpha.2",
+	}, {
+		in:      "next",
+		wantErr: true,
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := semver.Parse(tc.in)
+			if tc.wantErr {
+				require.ErrorIs(t, err, semver.ErrInvalidVersion)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.str, got.String())
+		})
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	ordered := []string{
+		"0.9",
+		"1.2.0-alpha",
+		"1.2.0-alpha.1",
+		"1.2.0-alpha.beta",
+		"1.2.0-beta.2",
+		"1.2.0-beta.11",
+		"1.2.0-rc1",
+		"1.2",
+		"1.2.1",
+		"1.10",
+		"2.0",
+	}
+	versions := make([]semver.Version, 0, len(ordered))
+	for _, s := range ordered {
+		v, err := semver.Parse(s)
+		require.NoError(t, err)
+		versions = append(versions, v)
+	}
+	for i, v := range versions {
+		for j, o := range versions {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			assert.Equal(t, want, v.Compare(o), "%s <=> %s", v, o)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package sh
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -9,14 +11,46 @@ import (
 	"strings"
 )
 
+// Options control how the command is run.
+type Options struct {
+	// Dir is the working directory of the command, the current one if empty.
+	Dir string
+	// Env is a list of environment variables, overriding the current ones.
+	Env map[string]string
+	// Stdin is the input of the command, none if nil.
+	Stdin io.Reader
+	// Stderr receives the standard error of the command, discarded if nil.
+	Stderr io.Writer
+}
+
 // Run runs the given command with the given arguments.
 func Run(cmd string, args ...string) error {
-	_, err := doExec(nil, os.Stdout, os.Stderr, cmd, args...)
+	return RunIn("", cmd, args...)
+}
+
+// RunIn runs the given command with the given arguments, within the given
+// directory. The working directory of the process isn't changed, so the
+// commands can be run concurrently. An empty directory is the current one.
+func RunIn(dir, cmd string, args ...string) error {
+	_, err := doExec(context.Background(), Options{
+		Dir:    dir,
+		Stdin:  os.Stdin,
+		Stderr: os.Stderr,
+	}, os.Stdout, cmd, args...)
 	return err
 }
 
-// doExec executes the command, piping its stdout and stderr to the given
-// writers. If the command fails, it will return an error that, if returned
+// Output runs the given command with the given arguments, and options, and
+// returns its standard output. The command is killed, if the context is done
+// before the command completes.
+func Output(ctx context.Context, opts Options, cmd string, args ...string) (string, error) {
+	var out bytes.Buffer
+	_, err := doExec(ctx, opts, &out, cmd, args...)
+	return out.String(), err
+}
+
+// doExec executes the command with the options, piping its stdout to the
+// given writer. If the command fails, it will return an error that, if returned
 // from a target or mg.Deps call, will cause mage to exit with the same code as
 // the command failed with. Env is a list of environment variables to set when
 // running the command, these override the current environment variables set
@@ -27,19 +61,25 @@ func Run(cmd string, args ...string) error {
 // Ran reports if the command ran (rather than was not found or not executable).
 // Code reports the exit code the command returned if it ran. If err == nil, ran
 // is always true and code is always 0.
-func doExec(env map[string]string, stdout, stderr io.Writer, cmd string, args ...string) (bool, error) {
+func doExec(
+	ctx context.Context, opts Options, stdout io.Writer,
+	cmd string, args ...string,
+) (bool, error) {
 	expand := func(s string) string {
-		s2, ok := env[s]
+		s2, ok := opts.Env[s]
 		if ok {
 			return s2
 		}
 		return os.Getenv(s)
 	}
 	cmd = os.Expand(cmd, expand)
+	// The arguments are expanded into a copy, as they may be shared.
+	expanded := make([]string, len(args))
 	for i := range args {
-		args[i] = os.Expand(args[i], expand)
+		expanded[i] = os.Expand(args[i], expand)
 	}
-	ran, code, err := run(env, stdout, stderr, cmd, args...)
+	args = expanded
+	ran, code, err := run(ctx, opts, stdout, cmd, args...)
 	if err == nil {
 		return true, nil
 	}
@@ -54,15 +94,19 @@ func doExec(env map[string]string, stdout, stderr io.Writer, cmd string, args ..
 		cmd, strings.Join(args, " "), err)
 }
 
-func run(env map[string]string, stdout, stderr io.Writer, cmd string, args ...string) (bool, int, error) {
-	c := exec.Command(cmd, args...)
+func run(
+	ctx context.Context, opts Options, stdout io.Writer,
+	cmd string, args ...string,
+) (bool, int, error) {
+	c := exec.CommandContext(ctx, cmd, args...)
+	c.Dir = opts.Dir
 	c.Env = os.Environ()
-	for k, v := range env {
+	for k, v := range opts.Env {
 		c.Env = append(c.Env, k+"="+v)
 	}
-	c.Stderr = stderr
+	c.Stderr = opts.Stderr
 	c.Stdout = stdout
-	c.Stdin = os.Stdin
+	c.Stdin = opts.Stdin
 
 	err := c.Run()
 	return cmdRan(err), exitStatus(err), err
@@ -0,0 +1,46 @@
+package sh_test
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/sh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunIn(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dir, "marker"), nil, 0o600))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	args := []string{"-f", "$MARKER"}
+	t.Setenv("MARKER", "marker")
+
+	require.NoError(t, sh.RunIn(dir, "test", args...))
+
+	assert.Equal(t, []string{"-f", "$MARKER"}, args)
+	got, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, wd, got)
+	err = sh.RunIn(t.TempDir(), "test", args...)
+	require.EqualError(t, err, `running "test -f marker" failed with exit code 1`)
+}
+
+func TestOutput(t *testing.T) {
+	dir := t.TempDir()
+	var stderr strings.Builder
+
+	out, err := sh.Output(t.Context(), sh.Options{
+		Dir:    dir,
+		Env:    map[string]string{"GREETING": "hello"},
+		Stdin:  strings.NewReader("input\n"),
+		Stderr: &stderr,
+	}, "sh", "-c", "pwd; printenv GREETING; cat; echo oops >&2")
+
+	require.NoError(t, err)
+	assert.Equal(t, dir+"\nhello\ninput\n", out)
+	assert.Equal(t, "oops\n", stderr.String())
+}
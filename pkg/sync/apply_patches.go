@@ -6,11 +6,11 @@ import (
 	"strings"
 
 	"github.com/openshift-knative/deviate/pkg/errors"
-	pkgfiles "github.com/openshift-knative/deviate/pkg/files"
 	"github.com/openshift-knative/deviate/pkg/log/color"
-	"github.com/openshift-knative/deviate/pkg/sh"
 )
 
+const applyPatchesMessage = ":fire: Apply carried patches"
+
 func (o Operation) applyPatches() error {
 	o.Println("- Apply patches if present")
 	patchesDir := path.Join(o.Path, "openshift", "patches")
@@ -26,18 +26,12 @@ func (o Operation) applyPatches() error {
 		}
 		filePath := path.Join(patchesDir, file.Name())
 		o.Printf("-- Applying %s\n", color.Blue(filePath))
-
-		// TODO: Consider rewriting this to Go native code instead shell invocation.
-		err = pkgfiles.WithinDirectory(o.Path, func() error {
-			return errors.Wrap(sh.Run("git", "apply", filePath),
-				ErrSyncFailed)
-		})
-		if err != nil {
+		if err = o.ApplyPatch(filePath); err != nil {
 			return errors.Wrap(err, ErrSyncFailed)
 		}
 	}
 
 	return runSteps([]step{
-		o.commitChanges(":fire: Apply carried patches"),
+		o.commitChanges(stepPatches, applyPatchesMessage),
 	})
 }
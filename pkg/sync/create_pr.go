@@ -1,29 +1,25 @@
 package sync
 
 import (
-	"encoding/json"
 	"fmt"
 
+	"github.com/openshift-knative/deviate/pkg/config"
 	"github.com/openshift-knative/deviate/pkg/errors"
-	"github.com/openshift-knative/deviate/pkg/git"
-	"github.com/openshift-knative/deviate/pkg/github"
+	"github.com/openshift-knative/deviate/pkg/forge"
 	"github.com/openshift-knative/deviate/pkg/log/color"
 )
 
 func (o Operation) createSyncReleaseNextPR() error {
-	branches := o.Branches
-	return o.createPR(
-		o.triggerCIMessage(),
-		fmt.Sprintf(o.TriggerCIBody, branches.ReleaseNext, branches.Main),
-		branches.ReleaseNext,
-		branches.CheckPrPrefix+branches.ReleaseNext,
-	)
+	o, err := o.withRelease(nextRelease{})
+	if err != nil {
+		return err
+	}
+	return o.createPR(o.syncReleaseNextPR())
 }
 
-func (o Operation) createPR(title, body, base, head string) error {
-	o.Println("Create a sync PR for:", color.Blue(base))
-	pr := createPR{Operation: o, title: title, body: body, base: base, head: head}
-	url, err := pr.active()
+func (o Operation) createPR(pr createPR) error {
+	o.Println("Create a sync PR for:", color.Blue(pr.base))
+	existing, err := pr.find()
 	if err != nil {
 		if errors.Is(err, errPrNotFound) {
 			return pr.open()
@@ -31,9 +27,25 @@ func (o Operation) createPR(title, body, base, head string) error {
 		return err
 	}
 
-	o.Printf("Thr PR for %s is already active: %s\n",
-		color.Blue(base), color.Yellow(*url))
-	return nil
+	o.Printf("The PR for %s is already active: %s\n",
+		color.Blue(pr.base), color.Yellow(existing.URL))
+	return pr.reconcile(existing)
+}
+
+func (o Operation) newPR(title, body, base, head string) createPR {
+	return createPR{Operation: o, title: title, body: body, base: base, head: head}
+}
+
+func (o Operation) syncReleaseNextPR() createPR {
+	branches := o.Branches
+	pr := o.newPR(
+		o.triggerCIMessage(),
+		fmt.Sprintf(o.TriggerCIBody, branches.ReleaseNext, branches.Main),
+		branches.ReleaseNext,
+		branches.CheckPrPrefix+branches.ReleaseNext,
+	)
+	pr.settings = o.PullRequests.ReleaseNext
+	return pr
 }
 
 type createPR struct {
@@ -42,73 +54,82 @@ type createPR struct {
 	body  string
 	base  string
 	head  string
+	// labels are added to the opened PR, besides the sync labels.
+	labels []string
+	// settings of the kind of the PR, applied when it's opened.
+	settings config.PullRequest
 }
 
 var errPrNotFound = errors.New("PR not found")
 
 func (c createPR) active() (*string, error) {
-	repo, err := c.repository()
+	req, err := c.find()
 	if err != nil {
-		return nil, errors.Wrap(err, ErrSyncFailed)
-	}
-	args := []string{
-		"pr", "list",
-		"--repo", repo,
-		"--state", "open",
-		"--author", "@me",
-		"--search", c.title,
-		"--json", "url",
+		return nil, err
 	}
-	for _, label := range c.SyncLabels {
-		args = append(args, "--label", label)
-	}
-	cl := github.NewClient(args...)
-	cl.DisableColor = true
-	cl.ProjectDir = c.Path
-	buff, err := cl.Execute(c.Context)
+	return &req.URL, nil
+}
+
+func (c createPR) find() (*forge.Request, error) {
+	f, err := c.forge()
 	if err != nil {
-		return nil, errors.Wrap(err, ErrSyncFailed)
+		return nil, err
 	}
-	un := make([]map[string]interface{}, 0)
-	err = json.Unmarshal(buff, &un)
+	req, err := f.FindOpen(c.Context, c.head, c.base)
 	if err != nil {
+		if errors.Is(err, forge.ErrNotFound) {
+			return nil, errPrNotFound
+		}
 		return nil, errors.Wrap(err, ErrSyncFailed)
 	}
-
-	if len(un) > 0 {
-		u := fmt.Sprintf("%s", un[0]["url"])
-		return &u, nil
-	}
-	return nil, errPrNotFound
+	return req, nil
 }
 
 func (c createPR) open() error {
-	repo, err := c.repository()
+	if c.DryRun {
+		c.Println(color.Yellow(fmt.Sprintf("- Skipping opening of the PR of %s "+
+			"into %s, because of dry run", c.head, c.base)))
+		return nil
+	}
+	f, err := c.forge()
+	if err != nil {
+		return err
+	}
+	req, err := f.Open(c.Context, forge.NewRequest{
+		Title: c.title,
+		Body:  c.changelog(c.baseRef()).body,
+		Head:  c.head,
+		Base:  c.base,
+		Draft: c.settings.Draft,
+	})
 	if err != nil {
 		return errors.Wrap(err, ErrSyncFailed)
 	}
-	args := []string{
-		"pr", "create",
-		"--repo", repo,
-		"--body", c.body,
-		"--title", c.title,
-		"--base", c.base,
-		"--head", c.head,
+	c.Println("PR opened:", color.Yellow(req.URL))
+	if err = f.AddLabels(c.Context, req.Number, c.allLabels()...); err != nil {
+		return errors.Wrap(err, ErrSyncFailed)
 	}
-	for _, label := range c.SyncLabels {
-		args = append(args, "--label", label)
+	// The PR is already opened, so failing to route it isn't fatal.
+	if err = f.Configure(c.Context, req, c.forgeSettings()); err != nil {
+		c.Println(color.Yellow(fmt.Sprintf("- Can't fully configure the PR: %v", err)))
 	}
-	cl := github.NewClient(args...)
-	cl.ProjectDir = c.Path
-	buff, err := cl.Execute(c.Context)
-	defer c.Println("Github client:", string(buff))
-	return errors.Wrap(err, ErrSyncFailed)
+	return nil
 }
 
-func (c createPR) repository() (string, error) {
-	addr, err := git.ParseAddress(c.Downstream)
-	if err != nil {
-		return "", errors.Wrap(err, ErrSyncFailed)
+func (c createPR) forgeSettings() forge.Settings {
+	return forge.Settings{
+		Reviewers:     c.settings.Reviewers,
+		TeamReviewers: c.settings.TeamReviewers,
+		Assignees:     c.settings.Assignees,
+		Milestone:     c.settings.Milestone,
+		AutoMerge:     forge.MergeMethod(c.settings.AutoMerge),
 	}
-	return addr.Path, nil
+}
+
+func (o Operation) forge() (forge.Forge, error) {
+	f, err := forge.New(o.Downstream, forge.Options{
+		Type: forge.Type(o.Config.Forge.Type),
+		URL:  o.Config.Forge.URL,
+	})
+	return f, errors.Wrap(err, ErrSyncFailed)
 }
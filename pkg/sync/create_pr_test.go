@@ -0,0 +1,20 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePR_DryRun(t *testing.T) {
+	f := newFixture(t)
+	gh := newFakeGitHub(t)
+	o := f.operation(gh.config() + "dryRun: true\n")
+	pr := o.newPR("Sync release-1.0", "Sync body", "release-1.0", "ci/release-1.0")
+
+	require.NoError(t, o.createPR(pr))
+
+	assert.Empty(t, gh.take())
+	assert.Empty(t, gh.pulls)
+}
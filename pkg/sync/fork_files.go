@@ -9,9 +9,9 @@ func (o Operation) addForkFiles(rel release) step {
 	return multiStep([]step{
 		o.removeUnwantedUpstreamFiles,
 		o.unpackForkOntoWorkspace,
-		o.commitChanges(o.Config.Messages.ApplyForkFiles),
+		o.commitChanges(stepForkFiles, o.Config.Messages.ApplyForkFiles),
 		o.generateImages(rel),
-		o.commitChanges(o.Config.Messages.ImagesGenerated),
+		o.commitChanges(stepImages, o.Config.Messages.ImagesGenerated),
 	}).runSteps
 }
 
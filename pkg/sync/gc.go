@@ -0,0 +1,147 @@
+package sync
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/openshift-knative/deviate/pkg/config/git"
+	"github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/forge"
+	"github.com/openshift-knative/deviate/pkg/log/color"
+)
+
+// GarbageCollect closes the sync PRs, and deletes the sync branches, of
+// releases that are no longer resynced.
+func (o Operation) GarbageCollect() error {
+	o.Println("Clean up stale sync PRs and branches")
+	stale, err := o.staleSyncBranches()
+	if err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		o.Println("No stale sync branches found")
+		return nil
+	}
+	o.Printf("Found stale sync branches: %s\n",
+		color.Blue(fmt.Sprintf("%+q", stale)))
+	for _, branch := range stale {
+		if err = o.collect(branch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o Operation) cleanup() error {
+	if !o.Cleanup {
+		return nil
+	}
+	return o.GarbageCollect()
+}
+
+func (o Operation) collect(branch string) error {
+	pr := o.stalePR(branch)
+	existing, err := pr.find()
+	switch {
+	case err == nil:
+		if err = pr.close(existing); err != nil {
+			return err
+		}
+	case !errors.Is(err, errPrNotFound):
+		return err
+	}
+	if o.DryRun {
+		o.Println(color.Yellow(fmt.Sprintf(
+			"- Skipping deletion of %s, because of dry run", branch)))
+		return nil
+	}
+	o.Println("- Deleting branch:", color.Blue(branch))
+	remote := git.Remote{Name: "downstream", URL: o.Downstream}
+	return errors.Wrap(o.DeleteRemoteBranch(remote, branch), ErrSyncFailed)
+}
+
+func (o Operation) stalePR(branch string) createPR {
+	base := strings.TrimPrefix(branch, o.CheckPrPrefix)
+	return o.newPR("", fmt.Sprintf(o.StalePR, base), base, branch)
+}
+
+func (c createPR) close(req *forge.Request) error {
+	if c.DryRun {
+		c.Println(color.Yellow(fmt.Sprintf(
+			"- Skipping closing of %s, because of dry run", req.URL)))
+		return nil
+	}
+	f, err := c.forge()
+	if err != nil {
+		return err
+	}
+	if err = f.Comment(c.Context, req.Number, c.body); err != nil {
+		return errors.Wrap(err, ErrSyncFailed)
+	}
+	if err = f.Close(c.Context, req.Number); err != nil {
+		return errors.Wrap(err, ErrSyncFailed)
+	}
+	c.Println("- PR closed:", color.Yellow(req.URL))
+	return nil
+}
+
+// staleSyncBranches lists the downstream sync branches, of the release-next,
+// and the releases, that are outside the resync window. The sync branches of
+// the releases are left alone, if the releases aren't resynced at all.
+func (o Operation) staleSyncBranches() ([]string, error) {
+	if o.CheckPrPrefix == "" {
+		return nil, fmt.Errorf("%w: sync branches can't be told apart "+
+			"from the releases, without the check PR prefix", ErrSyncFailed)
+	}
+	window, err := o.resyncWindow()
+	if err != nil {
+		return nil, err
+	}
+	refs, err := o.ListRemote(git.Remote{Name: "downstream", URL: o.Downstream})
+	if err != nil {
+		return nil, errors.Wrap(err, ErrSyncFailed)
+	}
+	re := regexp.MustCompile(o.DownstreamReleases)
+	stale := make([]string, 0)
+	for _, ref := range refs {
+		if !ref.Name().IsBranch() {
+			continue
+		}
+		branch := ref.Name().Short()
+		base, ok := strings.CutPrefix(branch, o.CheckPrPrefix)
+		if !ok || slices.Contains(window, base) {
+			continue
+		}
+		if base == o.ReleaseNext || (o.Enabled && re.MatchString(base)) {
+			stale = append(stale, branch)
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+// resyncWindow lists the downstream branches that have sync branches.
+func (o Operation) resyncWindow() ([]string, error) {
+	window := make([]string, 0, o.NumberOf+1)
+	if !o.SkipCheckPr {
+		window = append(window, o.ReleaseNext)
+	}
+	if !o.Enabled {
+		return window, nil
+	}
+	releases, err := o.releasesToResync(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range releases {
+		_, downstream, berr := releaseBranches(o.Config, rel)
+		if berr != nil {
+			return nil, berr
+		}
+		window = append(window, downstream)
+	}
+	return window, nil
+}
@@ -0,0 +1,51 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperation_StaleSyncBranches(t *testing.T) {
+	tcs := []struct {
+		name   string
+		config string
+		want   []string
+	}{{
+		name:   "outside the resync window",
+		config: "resyncReleases:\n  enabled: true\n  numberOf: 2\n",
+		want:   []string{"ci/release-1.0", "ci/release-1.1"},
+	}, {
+		name: "end of life",
+		config: "resyncReleases:\n  enabled: true\n  numberOf: 2\n" +
+			"branches:\n  endOfLife: ['1.3']\n",
+		want: []string{"ci/release-1.0", "ci/release-1.3"},
+	}, {
+		name:   "resync disabled",
+		config: "resyncReleases:\n  enabled: false\n",
+		want:   []string{},
+	}, {
+		name:   "check PR skipped",
+		config: "branches:\n  skipCheckPr: true\n",
+		want:   []string{"ci/release-next"},
+	}}
+	f := newFixture(t)
+	for _, branch := range []string{"release-1.0", "release-1.1", "release-1.2", "release-1.3"} {
+		f.commit(branch, map[string]string{"version.txt": branch + "\n"})
+		f.push(branch)
+		f.pushAs(branch, "ci/"+branch)
+	}
+	f.pushAs("main", "ci/release-next")
+	f.pushAs("main", "ci/feature")
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			o := f.operation(tc.config)
+
+			stale, err := o.staleSyncBranches()
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, stale)
+		})
+	}
+}
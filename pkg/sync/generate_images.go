@@ -1,11 +1,13 @@
 package sync
 
 import (
+	"encoding/json"
 	"os"
 	"path"
 	"strings"
 
 	"github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/sh"
 	"github.com/openshift-knative/hack/pkg/dockerfilegen"
 	"sigs.k8s.io/yaml"
 )
@@ -25,10 +27,55 @@ func (o Operation) generateImages(rel release) step {
 		if err != nil {
 			return err
 		}
-		return dockerfilegen.GenerateDockerfiles(params.Params)
+		return generateDockerfiles(params.Params)
 	}
 }
 
+// GenerateCommand is the hidden command of the binary, that generates the
+// images in a separate process, as dockerfilegen changes the working
+// directory of the process it runs in.
+const GenerateCommand = "generate-images"
+
+// GenerateDockerfiles generates the images with the dockerfilegen params,
+// read from the JSON file. It's run by the GenerateCommand.
+func GenerateDockerfiles(paramsFile string) error {
+	bytes, err := os.ReadFile(paramsFile)
+	if err != nil {
+		return errors.Wrap(err, ErrSyncFailed)
+	}
+	var params dockerfilegen.Params
+	if err = json.Unmarshal(bytes, &params); err != nil {
+		return errors.Wrap(err, ErrSyncFailed)
+	}
+	return errors.Wrap(dockerfilegen.GenerateDockerfiles(params), ErrSyncFailed)
+}
+
+// generateDockerfiles runs the GenerateCommand of the current executable
+// within the root directory, so the working directory of this process stays
+// intact, while the other releases are processed concurrently.
+func generateDockerfiles(params dockerfilegen.Params) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return errors.Wrap(err, ErrSyncFailed)
+	}
+	f, err := os.CreateTemp("", "dockerfilegen-*.json")
+	if err != nil {
+		return errors.Wrap(err, ErrSyncFailed)
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	if err = json.NewEncoder(f).Encode(params); err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, ErrSyncFailed)
+	}
+	if err = f.Close(); err != nil {
+		return errors.Wrap(err, ErrSyncFailed)
+	}
+	return errors.Wrap(sh.RunIn(params.RootDir, exe, GenerateCommand, f.Name()),
+		ErrSyncFailed)
+}
+
 func tempProjectFile(o Operation, rel release) (string, func(), error) {
 	closer := func() {}
 	f, err := os.CreateTemp("", "project-*.yaml")
@@ -41,10 +88,15 @@ func tempProjectFile(o Operation, rel release) (string, func(), error) {
 	closer = func() {
 		_ = os.Remove(f.Name())
 	}
+	name := upstreamToName(o.Upstream)
+	tag, err := rel.Tag(o.Tags, name)
+	if err != nil {
+		return "", closer, err
+	}
 	data := map[string]any{
 		"project": map[string]any{
-			"tag":         rel.Tag(),
-			"imagePrefix": upstreamToName(o.Upstream),
+			"tag":         tag,
+			"imagePrefix": name,
 		},
 	}
 	bytes, err := yaml.Marshal(data)
@@ -0,0 +1,54 @@
+package sync
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperation_GenerateImagesConcurrently(t *testing.T) {
+	f := newFixture(t)
+	o := f.operation("")
+	t.Chdir(f.dir)
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	project := *o.Project
+	project.Path = "project"
+	cfg := *o.Config
+	cfg.DockerfileGen.Skip = false
+	cfg.DockerfileGen.RootDir = "project"
+	o.Project = &project
+	o.Config = &cfg
+	o.Concurrency = 4
+	releases := make([]release, 0, 4)
+	for _, version := range []string{"1.0", "1.1", "1.2", "1.3"} {
+		releases = append(releases, parseRelease(t, version))
+	}
+	action := func(o Operation, rel release) error {
+		root := o.DockerfileGen.RootDir
+		writeFile(t, path.Join(root, "go.mod"), "module example.org/app\n\ngo 1.24\n")
+		writeFile(t, path.Join(root, "cmd", "app", "main.go"), "package main\n")
+		if err := o.generateImages(rel)(); err != nil {
+			return err
+		}
+		assert.FileExists(t, path.Join(root, "openshift", "ci-operator",
+			"knative-images", "app", "Dockerfile"), rel)
+		return nil
+	}
+
+	require.NoError(t, o.forEachRelease(releases, action))
+
+	after, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, wd, after)
+	assert.NoDirExists(t, path.Join(f.dir, "project", "openshift"))
+}
+
+func writeFile(tb testing.TB, pth, content string) {
+	tb.Helper()
+	require.NoError(tb, os.MkdirAll(path.Dir(pth), 0o750))
+	require.NoError(tb, os.WriteFile(pth, []byte(content), 0o600))
+}
@@ -0,0 +1,248 @@
+package sync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path"
+	gosync "sync"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/openshift-knative/deviate/pkg/config/git"
+	"github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/log/color"
+	"github.com/openshift-knative/deviate/pkg/metadata"
+	"github.com/openshift-knative/deviate/pkg/provenance"
+)
+
+// journalFile is the location of the journal, within the git directory of
+// the project.
+const journalFile = metadata.Name + "/journal.json"
+
+// Keys of the steps, that aren't done per release, in the journal.
+const (
+	journalTags          = "tags"
+	journalReleaseNext   = "release-next"
+	journalTriggerCI     = "trigger-ci"
+	journalReleaseNextPR = "release-next-pr"
+	journalCleanup       = "cleanup"
+)
+
+// journal records the steps completed by the sync, so the sync can be
+// resumed, if it was interrupted. The journal is valid if neither the
+// configuration, nor the checkout of the project, have changed since. A
+// completed step is still valid if the upstream branch, the step was based on,
+// hasn't changed since either.
+type journal struct {
+	RunID string `json:"runId"`
+	// Config is the digest of the configuration the steps were done with.
+	Config string `json:"config"`
+	// Head is the branch, or the commit if the head was detached, that was
+	// checked out before the sync.
+	Head       string                  `json:"head"`
+	HeadCommit string                  `json:"headCommit"`
+	Steps      map[string]journalEntry `json:"steps"`
+
+	file string
+	// repo is the project repository, the validity of the steps is checked
+	// against, as the steps may be done in clones.
+	repo git.Repository
+	// mu guards the steps, recorded concurrently.
+	mu gosync.Mutex
+}
+
+type journalEntry struct {
+	// Upstream is the upstream commit the step was based on.
+	Upstream  string    `json:"upstream,omitempty"`
+	Completed time.Time `json:"completed"`
+}
+
+// openJournal starts a new journal, or opens the one of the interrupted
+// sync, if the sync is resumed.
+func (o Operation) openJournal() (*journal, error) {
+	digest, err := o.configDigest()
+	if err != nil {
+		return nil, err
+	}
+	gitDir, err := o.GitDir()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrSyncFailed)
+	}
+	file := path.Join(gitDir, journalFile)
+	head, err := o.Repository.Head()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrSyncFailed)
+	}
+	if o.Resume {
+		j, rerr := o.readJournal(file, digest, head)
+		if j != nil || rerr != nil {
+			return j, rerr
+		}
+	}
+	j := &journal{
+		RunID:      o.RunID,
+		Config:     digest,
+		Head:       head.Name().String(),
+		HeadCommit: head.Hash().String(),
+		Steps:      make(map[string]journalEntry),
+		file:       file,
+		repo:       o.Repository,
+	}
+	if j.RunID == "" {
+		j.RunID = provenance.NewRunID()
+	}
+	return j, j.save()
+}
+
+// readJournal reads the journal of the interrupted sync. No journal is
+// returned if it's absent, or if the configuration, or the checked out head,
+// have changed since.
+func (o Operation) readJournal(file, digest string, head *plumbing.Reference) (*journal, error) {
+	bytes, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		o.Println(color.Yellow("No interrupted sync to resume, starting over"))
+		return nil, nil //nolint:nilnil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, ErrSyncFailed)
+	}
+	j := &journal{file: file, repo: o.Repository}
+	if err = json.Unmarshal(bytes, j); err != nil {
+		return nil, errors.Wrap(err, ErrSyncFailed)
+	}
+	if j.Config != digest {
+		o.Println(color.Yellow("The configuration has changed since " +
+			"the interrupted sync, starting over"))
+		return nil, nil //nolint:nilnil
+	}
+	if j.Head != head.Name().String() || j.HeadCommit != head.Hash().String() {
+		o.Println(color.Yellow("The checkout has changed since " +
+			"the interrupted sync, starting over"))
+		return nil, nil //nolint:nilnil
+	}
+	if j.Steps == nil {
+		j.Steps = make(map[string]journalEntry)
+	}
+	o.Printf("Resuming the interrupted sync %s, with %d completed steps\n",
+		color.Blue(j.RunID), len(j.Steps))
+	if len(j.Steps) > 0 {
+		upstream := git.Remote{Name: "upstream", URL: o.Upstream}
+		if err = o.Fetch(upstream); err != nil {
+			return nil, errors.Wrap(err, ErrSyncFailed)
+		}
+	}
+	return j, nil
+}
+
+// journaled skips the step, if it was completed by the interrupted sync,
+// and is still valid. Otherwise, the step is run, and recorded as completed.
+func (o Operation) journaled(key, upstreamBranch string, st step) step {
+	j := o.journal
+	if j == nil {
+		return st
+	}
+	return func() error {
+		if j.completed(key, upstreamBranch) {
+			o.Println("Skipping step completed by the interrupted sync:",
+				color.Blue(key))
+			return nil
+		}
+		if err := st(); err != nil {
+			return err
+		}
+		return j.record(key, journalEntry{
+			Upstream:  upstreamCommit(o.Repository, upstreamBranch),
+			Completed: time.Now(),
+		})
+	}
+}
+
+// completed tells if the step was completed, and is still valid.
+func (j *journal) completed(key, upstreamBranch string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entry, ok := j.Steps[key]
+	if !ok {
+		return false
+	}
+	if entry.Upstream != upstreamCommit(j.repo, upstreamBranch) {
+		delete(j.Steps, key)
+		return false
+	}
+	return true
+}
+
+func (j *journal) record(key string, entry journalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Steps[key] = entry
+	return j.save()
+}
+
+func upstreamCommit(repo git.Repository, branch string) string {
+	if branch == "" {
+		return ""
+	}
+	ref := plumbing.NewRemoteReferenceName("upstream", branch)
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return ""
+	}
+	return hash.String()
+}
+
+// restoreHead checks out the branch, or the commit, that was checked out
+// before the sync.
+func (o Operation) restoreHead() error {
+	j := o.journal
+	if j == nil || j.Head == "" {
+		return nil
+	}
+	ref := plumbing.NewHashReference(plumbing.ReferenceName(j.Head),
+		plumbing.NewHash(j.HeadCommit))
+	current, err := o.Repository.Head()
+	if err == nil && current.Name() == ref.Name() &&
+		(ref.Name().IsBranch() || current.Hash() == ref.Hash()) {
+		return nil
+	}
+	name := ref.Name().Short()
+	if !ref.Name().IsBranch() {
+		name = j.HeadCommit
+	}
+	o.Println("Restoring the checkout of:", color.Blue(name))
+	return errors.Wrap(o.SwitchTo(ref), ErrSyncFailed)
+}
+
+func (o Operation) configDigest() (string, error) {
+	bytes, err := json.Marshal(o.Config)
+	if err != nil {
+		return "", errors.Wrap(err, ErrSyncFailed)
+	}
+	sum := sha256.Sum256(bytes)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// save writes the journal, the caller holds the lock, unless the journal is
+// not shared yet.
+func (j *journal) save() error {
+	bytes, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, ErrSyncFailed)
+	}
+	const dirPerm, filePerm = 0o750, 0o600
+	if err = os.MkdirAll(path.Dir(j.file), dirPerm); err != nil {
+		return errors.Wrap(err, ErrSyncFailed)
+	}
+	return errors.Wrap(os.WriteFile(j.file, bytes, filePerm), ErrSyncFailed)
+}
+
+// remove removes the journal, once the sync is completed.
+func (j *journal) remove() error {
+	err := os.Remove(j.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return errors.Wrap(err, ErrSyncFailed)
+}
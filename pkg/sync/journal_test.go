@@ -0,0 +1,128 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"slices"
+	"testing"
+
+	gitv5 "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/openshift-knative/deviate/pkg/config/git"
+	pkggit "github.com/openshift-knative/deviate/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errStepFailed = errors.New("step failed")
+
+func TestJournal_Record(t *testing.T) {
+	f := newFixture(t)
+	o := f.withJournal(f.operation(""))
+	runs := map[string]int{}
+
+	require.NoError(t, o.journaled("step", "main", counted(runs, "step", nil))())
+
+	assert.Equal(t, 1, runs["step"])
+	gitDir, err := o.GitDir()
+	require.NoError(t, err)
+	assert.Equal(t, path.Join(o.Project.Path, ".git"), gitDir)
+	bytes, err := os.ReadFile(path.Join(gitDir, journalFile))
+	require.NoError(t, err)
+	j := journal{}
+	require.NoError(t, json.Unmarshal(bytes, &j))
+	assert.Equal(t, o.RunID, j.RunID)
+	require.Contains(t, j.Steps, "step")
+	assert.Equal(t, f.head("main").String(), j.Steps["step"].Upstream)
+	require.NoError(t, o.journal.remove())
+	assert.NoFileExists(t, path.Join(gitDir, journalFile))
+}
+
+func TestJournal_Resume(t *testing.T) {
+	tcs := []struct {
+		name string
+		// change is done between the interrupted, and the resumed sync.
+		change  func(f *fixture, o Operation)
+		skipped []string
+	}{{
+		name:    "after a failure",
+		skipped: []string{"first", "upstream"},
+	}, {
+		name: "upstream moved",
+		change: func(f *fixture, _ Operation) {
+			f.commit("main", map[string]string{"moved.txt": "moved\n"})
+		},
+		skipped: []string{"first"},
+	}, {
+		name: "different head",
+		change: func(_ *fixture, o Operation) {
+			wt, err := o.Repository.(*pkggit.Repository).Worktree()
+			require.NoError(t, err)
+			require.NoError(t, wt.Checkout(&gitv5.CheckoutOptions{
+				Branch: plumbing.NewBranchReferenceName("other"),
+				Create: true,
+			}))
+		},
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+			o := f.operation("")
+			o.RunID = "interrupted"
+			o = f.withJournal(o)
+			runs := map[string]int{}
+			steps := func(o Operation) []step {
+				return []step{
+					o.journaled("first", "", counted(runs, "first", nil)),
+					o.journaled("upstream", "main", counted(runs, "upstream", nil)),
+					o.journaled("failing", "", counted(runs, "failing", errStepFailed)),
+				}
+			}
+			require.ErrorIs(t, runSteps(steps(o)), errStepFailed)
+			if tc.change != nil {
+				tc.change(f, o)
+			}
+			clear(runs)
+
+			o.Resume = true
+			o.RunID = "resumed"
+			resumed := f.withJournal(o)
+			err := runSteps(steps(resumed))
+
+			require.ErrorIs(t, err, errStepFailed)
+			for _, key := range []string{"first", "upstream", "failing"} {
+				want := 1
+				if slices.Contains(tc.skipped, key) {
+					want = 0
+				}
+				assert.Equal(t, want, runs[key], key)
+			}
+			wantRunID := "interrupted"
+			if len(tc.skipped) == 0 {
+				wantRunID = "resumed"
+			}
+			assert.Equal(t, wantRunID, resumed.RunID)
+		})
+	}
+}
+
+// withJournal opens the journal of the operation, with the upstream fetched,
+// as the steps of the sync do.
+func (f *fixture) withJournal(o Operation) Operation {
+	f.tb.Helper()
+	require.NoError(f.tb, o.Fetch(git.Remote{Name: "upstream", URL: o.Upstream}))
+	j, err := o.openJournal()
+	require.NoError(f.tb, err)
+	o.journal = j
+	o.RunID = j.RunID
+	return o
+}
+
+func counted(runs map[string]int, key string, err error) step {
+	return func() error {
+		runs[key]++
+		return err
+	}
+}
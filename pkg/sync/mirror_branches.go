@@ -4,13 +4,15 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
+	"slices"
 	"sort"
-	"strconv"
 	"text/template"
 
+	"github.com/openshift-knative/deviate/pkg/config"
 	"github.com/openshift-knative/deviate/pkg/config/git"
 	"github.com/openshift-knative/deviate/pkg/errors"
 	"github.com/openshift-knative/deviate/pkg/log/color"
+	"github.com/openshift-knative/deviate/pkg/semver"
 )
 
 func (o Operation) mirrorReleases() error {
@@ -23,11 +25,8 @@ func (o Operation) mirrorReleases() error {
 	}
 	if len(missing) > 0 {
 		o.Printf("Found missing releases: %s\n", color.Blue(fmt.Sprintf("%+q", missing)))
-		for _, rel := range missing {
-			err = o.mirrorRelease(rel)
-			if err != nil {
-				return err
-			}
+		if err = o.forEachRelease(missing, Operation.mirrorRelease); err != nil {
+			return err
 		}
 	} else {
 		o.Println("No missing releases found")
@@ -40,37 +39,46 @@ type release interface {
 	String() string
 	Name(tpl string) (string, error)
 	less(o release) bool
-	Tag() string
+	Tag(tags config.Tags, upstream string) (string, error)
 }
 
+// stdRelease is a release named after a semantic version. The version parts
+// are available to the release templates, as .Major, .Minor, .Patch and .Pre.
 type stdRelease struct {
-	Major, Minor int
+	semver.Version
 }
 
-func (r stdRelease) String() string {
-	return strconv.Itoa(r.Major) + "." + strconv.Itoa(r.Minor)
+func (r stdRelease) Name(tpl string) (string, error) {
+	return execTemplate("release", tpl, r)
 }
 
-func (r stdRelease) Name(tpl string) (string, error) {
-	eng, err := template.New("release").Parse(tpl)
+func (r stdRelease) Tag(tags config.Tags, upstream string) (string, error) {
+	return execTemplate("tag", tags.ImageTemplate, config.TagData{
+		Major:    r.Major,
+		Minor:    r.Minor,
+		Patch:    r.Patch,
+		Pre:      r.Pre,
+		Version:  r.String(),
+		Upstream: upstream,
+	})
+}
+
+func execTemplate(name, tpl string, data any) (string, error) {
+	eng, err := template.New(name).Parse(tpl)
 	if err != nil {
 		return "", errors.Wrap(err, ErrSyncFailed)
 	}
 	var buff bytes.Buffer
-	err = eng.Execute(&buff, r)
+	err = eng.Execute(&buff, data)
 	if err != nil {
 		return "", errors.Wrap(err, ErrSyncFailed)
 	}
 	return buff.String(), nil
 }
 
-func (r stdRelease) Tag() string {
-	return "knative-v" + r.String()
-}
-
 func (r stdRelease) less(o release) bool {
 	if so, ok := o.(stdRelease); ok {
-		return r.Major < so.Major || (r.Major == so.Major && r.Minor < so.Minor)
+		return r.Less(so.Version)
 	}
 	return false
 }
@@ -86,6 +94,10 @@ func (o Operation) findMissingDownstreamReleases() ([]release, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, ErrSyncFailed)
 	}
+	upstreamReleases, err = o.maintainedReleases(upstreamReleases, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	missing := make([]release, 0, len(upstreamReleases))
 	for _, candidate := range upstreamReleases {
@@ -105,14 +117,14 @@ func (o Operation) findMissingDownstreamReleases() ([]release, error) {
 }
 
 func (o Operation) listReleases(upstream bool) ([]release, error) {
-	url := o.Downstream
+	remote := git.Remote{Name: "downstream", URL: o.Downstream}
 	re := regexp.MustCompile(o.DownstreamReleases)
 	if upstream {
-		url = o.Upstream
+		remote = git.Remote{Name: "upstream", URL: o.Upstream}
 		re = regexp.MustCompile(o.UpstreamReleases)
 	}
 
-	refs, err := o.ListRemote(git.Remote{Name: "origin", URL: url})
+	refs, err := o.ListRemote(remote)
 	if err != nil {
 		return nil, errors.Wrap(err, ErrSyncFailed)
 	}
@@ -124,8 +136,17 @@ func (o Operation) listReleases(upstream bool) ([]release, error) {
 		if name.IsBranch() {
 			branch := name.Short()
 			if matches := re.FindStringSubmatch(branch); matches != nil {
-				version := stdRelease{atoi(matches[1]), atoi(matches[2])}
-				releases = append(releases, version)
+				rel, rerr := newRelease(re, matches)
+				if rerr != nil {
+					o.Printf("- Skipping branch %s: %v\n", branch, rerr)
+					continue
+				}
+				if !upstream {
+					if rel, err = toUpstream(o.VersionMapping, rel); err != nil {
+						return nil, err
+					}
+				}
+				releases = append(releases, rel)
 			}
 		}
 	}
@@ -136,10 +157,73 @@ func (o Operation) listReleases(upstream bool) ([]release, error) {
 	return releases, nil
 }
 
-func atoi(s string) int {
-	i, err := strconv.Atoi(s)
+// maintainedReleases filters out releases below the minimum release, the
+// skipped ones, and the given excluded ones.
+func (o Operation) maintainedReleases(releases []release, excluded []string) ([]release, error) {
+	var minimum *semver.Version
+	if o.MinimumRelease != "" {
+		v, err := semver.Parse(o.MinimumRelease)
+		if err != nil {
+			return nil, fmt.Errorf("%w: minimum release: %w", ErrSyncFailed, err)
+		}
+		minimum = &v
+	}
+	skipped, err := parseVersions(slices.Concat(o.SkipReleases, excluded))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]release, 0, len(releases))
+	for _, rel := range releases {
+		sr, ok := rel.(stdRelease)
+		if ok && !maintained(sr.Version, minimum, skipped) {
+			continue
+		}
+		result = append(result, rel)
+	}
+	return result, nil
+}
+
+func maintained(v semver.Version, minimum *semver.Version, skipped []semver.Version) bool {
+	if minimum != nil && v.Less(*minimum) {
+		return false
+	}
+	for _, s := range skipped {
+		if semver.SameRelease(s, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseVersions(versions []string) ([]semver.Version, error) {
+	result := make([]semver.Version, 0, len(versions))
+	for _, s := range versions {
+		v, err := semver.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: release list: %w", ErrSyncFailed, err)
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+// newRelease creates a release out of the branch name matches. The version
+// parts are taken from the major, minor, patch and pre named groups, if the
+// search expression defines them, or from the first two groups otherwise.
+func newRelease(re *regexp.Regexp, matches []string) (stdRelease, error) {
+	group := func(name string) string {
+		if idx := re.SubexpIndex(name); idx >= 0 {
+			return matches[idx]
+		}
+		return ""
+	}
+	major, minor := group("major"), group("minor")
+	if re.SubexpIndex("major") < 0 && len(matches) > 2 {
+		major, minor = matches[1], matches[2]
+	}
+	v, err := semver.New(major, minor, group("patch"), group("pre"))
 	if err != nil {
-		return 0
+		return stdRelease{}, errors.Wrap(err, ErrSyncFailed)
 	}
-	return i
+	return stdRelease{v}, nil
 }
@@ -0,0 +1,96 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/config"
+	"github.com/openshift-knative/deviate/pkg/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelease_Tag(t *testing.T) {
+	tcs := []struct {
+		name     string
+		release  release
+		template string
+		want     string
+		wantErr  bool
+	}{{
+		name:     "default",
+		release:  parseRelease(t, "1.2"),
+		template: "knative-v{{ .Version }}",
+		want:     "knative-v1.2",
+	}, {
+		name:     "version parts",
+		release:  parseRelease(t, "1.2.3-rc.1"),
+		template: "v{{ .Major }}.{{ .Minor }}.{{ .Patch }}{{ with .Pre }}-{{ . }}{{ end }}",
+		want:     "v1.2.3-rc.1",
+	}, {
+		name:     "upstream",
+		release:  parseRelease(t, "1.2"),
+		template: "{{ .Upstream }}-{{ .Version }}",
+		want:     "knative-1.2",
+	}, {
+		name:     "next default",
+		release:  nextRelease{},
+		template: "knative-next",
+		want:     "knative-next",
+	}, {
+		name:     "next version",
+		release:  nextRelease{},
+		template: "{{ .Upstream }}-{{ .Version }}",
+		want:     "knative-next",
+	}, {
+		name:     "unknown field",
+		release:  parseRelease(t, "1.2"),
+		template: "{{ .Build }}",
+		wantErr:  true,
+	}, {
+		name:     "invalid",
+		release:  parseRelease(t, "1.2"),
+		template: "{{ .Version ",
+		wantErr:  true,
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			tags := config.Tags{ImageTemplate: tc.template, ImageNextTemplate: tc.template}
+
+			got, err := tc.release.Tag(tags, "knative")
+
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrSyncFailed)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRelease_Name(t *testing.T) {
+	tcs := []struct {
+		release  release
+		template string
+		want     string
+	}{
+		{parseRelease(t, "1.2"), "release-{{ .Major }}.{{ .Minor }}", "release-1.2"},
+		{parseRelease(t, "1.2"), "release-v{{ .Major }}.{{ .Minor }}", "release-v1.2"},
+		{parseRelease(t, "1.2.3"), "release-{{ .Major }}.{{ .Minor }}.{{ .Patch }}", "release-1.2.3"},
+		{nextRelease{}, "release-{{ .Major }}.{{ .Minor }}", "release-next"},
+	}
+	for _, tc := range tcs {
+		got, err := tc.release.Name(tc.template)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got, tc.template)
+	}
+	_, err := parseRelease(t, "1.2").Name("release-{{ .Major ")
+	require.ErrorIs(t, err, ErrSyncFailed)
+}
+
+func parseRelease(tb testing.TB, version string) stdRelease {
+	tb.Helper()
+	v, err := semver.Parse(version)
+	require.NoError(tb, err)
+	return stdRelease{v}
+}
@@ -2,8 +2,10 @@ package sync
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/openshift-knative/deviate/pkg/config"
 	"github.com/openshift-knative/deviate/pkg/config/git"
 	"github.com/openshift-knative/deviate/pkg/errors"
 	"github.com/openshift-knative/deviate/pkg/log/color"
@@ -11,13 +13,22 @@ import (
 )
 
 func (o Operation) mirrorRelease(rel release) error {
-	return runSteps([]step{
+	o, err := o.withRelease(rel)
+	if err != nil {
+		return err
+	}
+	upstreamBranch, _, err := releaseBranches(o.Config, rel)
+	if err != nil {
+		return err
+	}
+	o.upstreamRef = upstreamBranch
+	return o.journaled("mirror/"+rel.String(), upstreamBranch, multiStep{
 		o.createNewRelease(rel),
 		o.addForkFiles(rel),
 		o.applyPatches,
 		o.switchToMain,
 		o.pushRelease(rel),
-	})
+	}.runSteps)()
 }
 
 func (o Operation) createNewRelease(rel release) step {
@@ -30,11 +41,12 @@ func (o Operation) createNewRelease(rel release) step {
 func (o Operation) pushRelease(rel release) step {
 	return func() error {
 		o.Printf("- Publishing release: %s\n", color.Blue(rel.String()))
-		branch, err := rel.Name(o.ReleaseTemplates.Downstream)
+		_, branch, err := releaseBranches(o.Config, rel)
 		if err != nil {
-			return errors.Wrap(err, ErrSyncFailed)
+			return err
 		}
-		pr := push{State: o.State, branch: branch}
+		// The release is missing downstream, so it's expected to be absent.
+		pr := push{State: o.State, branch: branch, lease: &plumbing.ZeroHash}
 		return runSteps(pr.steps())
 	}
 }
@@ -46,13 +58,9 @@ type createNewRelease struct {
 }
 
 func (r createNewRelease) step() error {
-	upstreamBranch, err := r.rel.Name(r.ReleaseTemplates.Upstream)
+	upstreamBranch, downstreamBranch, err := releaseBranches(r.Config, r.rel)
 	if err != nil {
-		return errors.Wrap(err, ErrSyncFailed)
-	}
-	downstreamBranch, err := r.rel.Name(r.ReleaseTemplates.Downstream)
-	if err != nil {
-		return errors.Wrap(err, ErrSyncFailed)
+		return err
 	}
 	return runSteps([]step{
 		r.fetch,
@@ -76,6 +84,8 @@ type push struct {
 	state.State
 	branch     string
 	skipDelete bool
+	// lease, if set, is the hash the downstream branch is expected at.
+	lease *plumbing.Hash
 }
 
 func (p push) steps() []step {
@@ -88,14 +98,24 @@ func (p push) steps() []step {
 
 func (p push) push() error {
 	refName := plumbing.NewBranchReferenceName(p.branch)
-	return publish(p.State, "release push", refName)
+	var opts []git.PushOption
+	if p.lease != nil {
+		opts = append(opts, git.WithLease(*p.lease))
+	}
+	return publish(p.State, "release push", refName, opts...)
 }
 
 func (p push) delete() error {
 	return errors.Wrap(p.DeleteBranch(p.branch), ErrSyncFailed)
 }
 
-func publish(state state.State, title string, refName plumbing.ReferenceName) error {
+// publish pushes the reference downstream. The references owned by deviate,
+// which are recreated on each sync, are force pushed. Other references are
+// only fast-forwarded, or overwritten if they match the given lease.
+func publish(
+	state state.State, title string, refName plumbing.ReferenceName,
+	opts ...git.PushOption,
+) error {
 	if state.DryRun {
 		state.Println(color.Yellow(fmt.Sprintf(
 			"- Skipping %s, because of dry run", title)))
@@ -105,5 +125,19 @@ func publish(state state.State, title string, refName plumbing.ReferenceName) er
 		Name: "downstream",
 		URL:  state.Downstream,
 	}
-	return errors.Wrap(state.Push(remote, refName), ErrSyncFailed)
+	if ownedRef(state.Config, refName) {
+		opts = append(opts, git.WithForce())
+	}
+	return errors.Wrap(state.Push(remote, refName, opts...), ErrSyncFailed)
+}
+
+// ownedRef tells if the reference is owned by deviate, so it can be force
+// pushed: the release-next branch and the sync branches.
+func ownedRef(cfg *config.Config, refName plumbing.ReferenceName) bool {
+	if !refName.IsBranch() {
+		return false
+	}
+	branch := refName.Short()
+	return branch == cfg.ReleaseNext ||
+		(cfg.CheckPrPrefix != "" && strings.HasPrefix(branch, cfg.CheckPrPrefix))
 }
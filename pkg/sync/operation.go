@@ -3,6 +3,7 @@ package sync
 import (
 	gitv5 "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/openshift-knative/deviate/pkg/config"
 	"github.com/openshift-knative/deviate/pkg/config/git"
 	"github.com/openshift-knative/deviate/pkg/errors"
 	"github.com/openshift-knative/deviate/pkg/log/color"
@@ -15,19 +16,45 @@ var ErrSyncFailed = errors.New("sync failed")
 // Operation performs sync - the upstream synchronization.
 type Operation struct {
 	state.State
+	// RunID identifies the run in the provenance of the commits.
+	RunID string
+	// Resume skips the steps completed by the interrupted sync, that are
+	// still valid.
+	Resume bool
+
+	// upstreamRef is the upstream branch the current changes are based on.
+	upstreamRef string
+	// journal records the completed steps, so the sync can be resumed.
+	journal *journal
 }
 
-func (o Operation) Run() error {
-	if err := runSteps([]step{
+// Run performs the sync. The completed steps are recorded in the journal,
+// until the sync is done. Finally, the branch that was checked out before
+// the sync is restored.
+func (o Operation) Run() (err error) {
+	if o.journal, err = o.openJournal(); err != nil {
+		return err
+	}
+	o.RunID = o.journal.RunID
+	defer func() {
+		err = errors.Join(err, o.restoreHead())
+	}()
+	if err = runSteps([]step{
 		o.mirrorReleases,
-		o.syncTags,
-		o.syncReleaseNext,
-		o.triggerCI,
-		o.createSyncReleaseNextPR,
+		o.journaled(journalTags, "", o.syncTags),
+		o.journaled(journalReleaseNext, o.Main, o.syncReleaseNext),
+		o.journaled(journalTriggerCI, o.Main, o.triggerCI),
+		o.journaled(journalReleaseNextPR, o.Main, o.createSyncReleaseNextPR),
+		o.journaled(journalCleanup, "", o.cleanup),
 	}); err != nil {
+		o.Println(color.Yellow("The sync was interrupted, run it with " +
+			"--resume to continue"))
 		return err
 	}
-	return o.switchToMain()
+	if err = o.switchToMain(); err != nil {
+		return err
+	}
+	return o.journal.remove()
 }
 
 func (o Operation) switchToMain() error {
@@ -42,10 +69,29 @@ func (o Operation) switchToMain() error {
 	)
 }
 
-func (o Operation) commitChanges(message string, onCommit ...step) step {
+// withRelease returns the operation with the configuration overrides of the
+// release applied.
+func (o Operation) withRelease(rel release) (Operation, error) {
+	var (
+		cfg config.Config
+		err error
+	)
+	if r, ok := rel.(stdRelease); ok {
+		cfg, err = o.Config.ForRelease(r.Version)
+	} else {
+		cfg, err = o.Config.ForReleaseNext()
+	}
+	if err != nil {
+		return o, errors.Wrap(err, ErrSyncFailed)
+	}
+	o.State.Config = &cfg
+	return o, nil
+}
+
+func (o Operation) commitChanges(kind, message string, onCommit ...step) step {
 	return func() error {
 		o.Println("- Committing changes:", message)
-		commit, err := o.CommitChanges(message)
+		commit, err := o.CommitChanges(o.commitProvenance(kind).Append(message))
 		if err != nil {
 			if errors.Is(err, gitv5.NoErrAlreadyUpToDate) {
 				o.Println("-- No changes to commit")
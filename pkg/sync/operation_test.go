@@ -0,0 +1,300 @@
+package sync
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	gosync "sync"
+	"testing"
+
+	gitv5 "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/openshift-knative/deviate/pkg/config"
+	"github.com/openshift-knative/deviate/pkg/errors"
+	pkggit "github.com/openshift-knative/deviate/pkg/git"
+	"github.com/openshift-knative/deviate/pkg/github"
+	"github.com/openshift-knative/deviate/pkg/log"
+	"github.com/openshift-knative/deviate/pkg/state"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain generates the images, when the test binary is run as the
+// GenerateCommand, like the deviate binary is, by the image generation.
+func TestMain(m *testing.M) {
+	const commandArgs = 3
+	if len(os.Args) == commandArgs && os.Args[1] == GenerateCommand {
+		if err := GenerateDockerfiles(os.Args[2]); err != nil {
+			_, _ = os.Stderr.WriteString(err.Error() + "\n")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fixture is a fork of a local upstream repository. The fork commits are
+// authored in the upstream working copy, on the fork/ prefixed branches, and
+// pushed to the downstream repository without the prefix.
+type fixture struct {
+	tb         testing.TB
+	dir        string
+	upstream   *gitv5.Repository
+	downstream string
+}
+
+func newFixture(tb testing.TB) *fixture {
+	tb.Helper()
+	dir := tb.TempDir()
+	opts := &gitv5.PlainInitOptions{
+		InitOptions: gitv5.InitOptions{DefaultBranch: plumbing.Main},
+	}
+	upstream, err := gitv5.PlainInitWithOptions(path.Join(dir, "upstream"), opts)
+	require.NoError(tb, err)
+	downstream := path.Join(dir, "downstream.git")
+	opts.Bare = true
+	_, err = gitv5.PlainInitWithOptions(downstream, opts)
+	require.NoError(tb, err)
+	_, err = upstream.CreateRemote(&gitconfig.RemoteConfig{
+		Name: "downstream",
+		URLs: []string{downstream},
+	})
+	require.NoError(tb, err)
+	f := &fixture{tb: tb, dir: dir, upstream: upstream, downstream: downstream}
+	f.commit("main", map[string]string{"README.md": "upstream\n"})
+	f.push("main")
+	return f
+}
+
+// commit commits the files onto the upstream branch, creating it from the
+// currently checked out one, if needed.
+func (f *fixture) commit(branch string, files map[string]string) plumbing.Hash {
+	f.tb.Helper()
+	wt, err := f.upstream.Worktree()
+	require.NoError(f.tb, err)
+	name := plumbing.NewBranchReferenceName(branch)
+	_, err = f.upstream.Reference(name, false)
+	if head, herr := f.upstream.Head(); herr == nil && head.Name() != name {
+		require.NoError(f.tb, wt.Checkout(&gitv5.CheckoutOptions{
+			Branch: name,
+			Create: err != nil,
+		}))
+	}
+	for name, content := range files {
+		pth := path.Join(f.dir, "upstream", name)
+		require.NoError(f.tb, os.MkdirAll(path.Dir(pth), 0o750))
+		require.NoError(f.tb, os.WriteFile(pth, []byte(content), 0o600))
+		_, err = wt.Add(name)
+		require.NoError(f.tb, err)
+	}
+	hash, err := wt.Commit("change on "+branch, &gitv5.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.org"},
+	})
+	require.NoError(f.tb, err)
+	return hash
+}
+
+// forkCommit commits the files onto the downstream branch, forking it from
+// the upstream one, if needed.
+func (f *fixture) forkCommit(branch string, files map[string]string) plumbing.Hash {
+	f.tb.Helper()
+	fork := plumbing.NewBranchReferenceName("fork/" + branch)
+	if _, err := f.upstream.Reference(fork, false); err != nil {
+		require.NoError(f.tb, f.upstream.Storer.SetReference(
+			plumbing.NewHashReference(fork, f.head(branch))))
+	}
+	hash := f.commit("fork/"+branch, files)
+	f.pushAs("fork/"+branch, branch)
+	return hash
+}
+
+// push pushes the upstream branches to the downstream, as they are.
+func (f *fixture) push(branches ...string) {
+	f.tb.Helper()
+	for _, branch := range branches {
+		f.pushAs(branch, branch)
+	}
+}
+
+func (f *fixture) pushAs(branch, downstreamBranch string) {
+	f.tb.Helper()
+	spec := gitconfig.RefSpec("+" + plumbing.NewBranchReferenceName(branch) +
+		":" + plumbing.NewBranchReferenceName(downstreamBranch))
+	err := f.upstream.Push(&gitv5.PushOptions{
+		RemoteName: "downstream",
+		RefSpecs:   []gitconfig.RefSpec{spec},
+	})
+	if !errors.Is(err, gitv5.NoErrAlreadyUpToDate) {
+		require.NoError(f.tb, err)
+	}
+}
+
+// head returns the commit of the branch, in the upstream working copy.
+func (f *fixture) head(branch string) plumbing.Hash {
+	f.tb.Helper()
+	ref, err := f.upstream.Reference(plumbing.NewBranchReferenceName(branch), true)
+	require.NoError(f.tb, err)
+	return ref.Hash()
+}
+
+// operation clones the downstream into the project, and creates the sync
+// operation of it, with the given configuration.
+func (f *fixture) operation(configYaml string) Operation {
+	f.tb.Helper()
+	projectPath := path.Join(f.dir, "project")
+	gr, err := gitv5.PlainOpen(projectPath)
+	if errors.Is(err, gitv5.ErrRepositoryNotExists) {
+		gr, err = gitv5.PlainClone(projectPath, false, &gitv5.CloneOptions{
+			URL: f.downstream,
+		})
+	}
+	require.NoError(f.tb, err)
+	configPath := path.Join(f.dir, ".deviate.yaml")
+	configYaml = "upstream: file://" + path.Join(f.dir, "upstream") + "\n" +
+		"downstream: file://" + f.downstream + "\n" +
+		"dockerfileGen:\n  skip: true\n" +
+		"commits:\n  author:\n    name: test\n    email: test@example.org\n" +
+		configYaml
+	require.NoError(f.tb, os.WriteFile(configPath, []byte(configYaml), 0o600))
+	project := config.Project{Path: projectPath, ConfigPath: configPath}
+	logger := log.TestingLogger{T: f.tb}
+	repo := &pkggit.Repository{
+		Context:    f.tb.Context(),
+		Project:    project,
+		Repository: gr,
+	}
+	cfg, err := config.New(project, logger, repo)
+	require.NoError(f.tb, err)
+	repo.Auth = cfg.Auth
+	repo.Commits = cfg.Commits
+	return Operation{State: state.State{
+		Config:     &cfg,
+		Project:    &project,
+		Repository: repo,
+		Context:    f.tb.Context(),
+		Logger:     logger,
+	}}
+}
+
+// commitOf returns the commit of the project HEAD.
+func commitOf(tb testing.TB, o Operation) *object.Commit {
+	tb.Helper()
+	repo, ok := o.Repository.(*pkggit.Repository)
+	require.True(tb, ok)
+	head, err := repo.Head()
+	require.NoError(tb, err)
+	c, err := repo.CommitObject(head.Hash())
+	require.NoError(tb, err)
+	return c
+}
+
+// fakeGitHub is the GitHub API of the downstream repository. The pull requests
+// are kept in memory, and the requests changing them are recorded.
+type fakeGitHub struct {
+	*httptest.Server
+	mu       gosync.Mutex
+	pulls    []*github.PullRequest
+	requests []apiRequest
+}
+
+// apiRequest is a recorded request, of the given kind of resource, like pulls,
+// labels, or comments.
+type apiRequest struct {
+	Method string
+	Kind   string
+	Number int
+	Body   map[string]any
+}
+
+func newFakeGitHub(tb testing.TB) *fakeGitHub {
+	tb.Helper()
+	g := &fakeGitHub{}
+	g.Server = httptest.NewServer(http.HandlerFunc(g.serve))
+	tb.Cleanup(g.Close)
+	return g
+}
+
+// config configures the forge of the fixture to be the fake.
+func (g *fakeGitHub) config() string {
+	return "forge:\n  type: github\n  url: " + g.URL + "\n"
+}
+
+// take returns the recorded requests, and forgets them.
+func (g *fakeGitHub) take() []apiRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	requests := g.requests
+	g.requests = nil
+	return requests
+}
+
+func (g *fakeGitHub) serve(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	req := apiRequest{Method: r.Method}
+	elems := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	req.Kind = elems[len(elems)-1]
+	if n, err := strconv.Atoi(req.Kind); err == nil {
+		req.Number, req.Kind = n, elems[len(elems)-2]
+	} else if len(elems) > 2 {
+		req.Number, _ = strconv.Atoi(elems[len(elems)-2])
+	}
+	if bytes, err := io.ReadAll(r.Body); err == nil && len(bytes) > 0 {
+		_ = json.Unmarshal(bytes, &req.Body)
+	}
+	var out any = map[string]any{}
+	switch {
+	case req.Method == http.MethodGet && req.Kind == "pulls":
+		out = g.find(r.URL.Query())
+	case req.Method == http.MethodGet:
+		out = []any{}
+	case req.Kind == "pulls" && req.Number == 0:
+		pr := &github.PullRequest{Number: len(g.pulls) + 1, State: "open"}
+		pr.Head.Ref, _ = req.Body["head"].(string)
+		pr.Base.Ref, _ = req.Body["base"].(string)
+		g.pulls = append(g.pulls, pr)
+		out = g.update(pr, req.Body)
+	case req.Kind == "pulls":
+		out = g.update(g.pulls[req.Number-1], req.Body)
+	case req.Kind == "labels":
+		pr := g.pulls[req.Number-1]
+		labels, _ := req.Body["labels"].([]any)
+		for _, l := range labels {
+			pr.Labels = append(pr.Labels, github.Label{Name: l.(string)})
+		}
+	}
+	if req.Method != http.MethodGet {
+		g.requests = append(g.requests, req)
+	}
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (g *fakeGitHub) find(q map[string][]string) []github.PullRequest {
+	_, head, _ := strings.Cut(q["head"][0], ":")
+	found := make([]github.PullRequest, 0)
+	for _, pr := range g.pulls {
+		if pr.State == "open" && pr.Head.Ref == head && pr.Base.Ref == q["base"][0] {
+			found = append(found, *pr)
+		}
+	}
+	return found
+}
+
+func (g *fakeGitHub) update(pr *github.PullRequest, fields map[string]any) *github.PullRequest {
+	for key, field := range map[string]*string{
+		"title": &pr.Title, "body": &pr.Body, "state": &pr.State,
+	} {
+		if v, ok := fields[key].(string); ok {
+			*field = v
+		}
+	}
+	return pr
+}
@@ -0,0 +1,109 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	gosync "sync"
+
+	"github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/log"
+	"github.com/openshift-knative/deviate/pkg/log/color"
+	"github.com/openshift-knative/deviate/pkg/metadata"
+)
+
+// releaseAction is done for each of the releases.
+type releaseAction func(o Operation, rel release) error
+
+// forEachRelease does the action for each of the releases. By default, the
+// releases are processed one by one, within the project, stopping at the
+// first failure. With a higher concurrency, the releases are processed
+// concurrently, each in a temporary clone of the project, and the failures
+// are aggregated once all the releases are processed.
+func (o Operation) forEachRelease(releases []release, action releaseAction) error {
+	if o.Concurrency <= 1 || len(releases) <= 1 {
+		for _, rel := range releases {
+			if err := action(o, rel); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	errs := make([]error, len(releases))
+	slots := make(chan struct{}, o.Concurrency)
+	var wg gosync.WaitGroup
+	for i, rel := range releases {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			errs[i] = o.inClone(rel, action)
+		}()
+	}
+	wg.Wait()
+	failed := make([]string, 0, len(releases))
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, releases[i].String())
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	o.Printf("Failed releases: %s\n", color.Red(fmt.Sprintf("%+q", failed)))
+	return errors.Join(errs...)
+}
+
+// inClone does the action within a temporary clone of the project, with the
+// messages labeled with the release. The clone is given by an absolute path,
+// like the project is, so the paths within it can be rebased onto the clone.
+func (o Operation) inClone(rel release, action releaseAction) error {
+	dir, err := os.MkdirTemp("", metadata.Name+"-*")
+	if err != nil {
+		return errors.Wrap(err, ErrSyncFailed)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	if dir, err = filepath.Abs(dir); err != nil {
+		return errors.Wrap(err, ErrSyncFailed)
+	}
+	repo, err := o.Repository.Clone(dir)
+	if err != nil {
+		return errors.Wrap(err, ErrSyncFailed)
+	}
+	project := *o.Project
+	project.Path = dir
+	cfg := *o.Config
+	cfg.DockerfileGen.RootDir = rebase(cfg.DockerfileGen.RootDir, o.Project.Path, dir)
+	o.State.Project = &project
+	o.State.Config = &cfg
+	o.State.Repository = repo
+	o.State.Logger = log.LabeledLogger{
+		Label:  color.Blue("[" + rel.String() + "]"),
+		Logger: o.Logger,
+	}
+	return action(o, rel)
+}
+
+// rebase moves the path within the project directory onto the other one.
+// Relative paths are resolved against the working directory.
+func rebase(pth, from, to string) string {
+	if pth == "" {
+		return to
+	}
+	abs, err := filepath.Abs(pth)
+	if err != nil {
+		return pth
+	}
+	if from, err = filepath.Abs(from); err != nil {
+		return pth
+	}
+	rel, err := filepath.Rel(from, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return pth
+	}
+	return filepath.Join(to, rel)
+}
@@ -0,0 +1,128 @@
+package sync
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperation_ForEachRelease(t *testing.T) {
+	tcs := []struct {
+		name        string
+		concurrency int
+		failing     []string
+		// processed are the releases the action is done for.
+		processed int
+		inClones  bool
+	}{{
+		name:        "serial",
+		concurrency: 1,
+		processed:   4,
+	}, {
+		name:        "serial stops at the first failure",
+		concurrency: 1,
+		failing:     []string{"1.1", "1.2"},
+		processed:   2,
+	}, {
+		name:        "concurrent",
+		concurrency: 2,
+		processed:   4,
+		inClones:    true,
+	}, {
+		name:        "concurrent aggregates the failures",
+		concurrency: 2,
+		failing:     []string{"1.1", "1.2"},
+		processed:   4,
+		inClones:    true,
+	}}
+	f := newFixture(t)
+	releases := make([]release, 0, 4)
+	for _, version := range []string{"1.0", "1.1", "1.2", "1.3"} {
+		releases = append(releases, parseRelease(t, version))
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			o := f.operation("")
+			o.Concurrency = tc.concurrency
+			var (
+				mu        gosync.Mutex
+				active    int
+				maxActive int
+				dirs      []string
+			)
+			action := func(o Operation, rel release) error {
+				mu.Lock()
+				active++
+				maxActive = max(maxActive, active)
+				dirs = append(dirs, o.Project.Path)
+				mu.Unlock()
+				defer func() {
+					mu.Lock()
+					active--
+					mu.Unlock()
+				}()
+				assert.DirExists(t, path.Join(o.Project.Path, ".git"))
+				assert.True(t, filepath.IsAbs(o.Project.Path))
+				time.Sleep(50 * time.Millisecond)
+				for _, failing := range tc.failing {
+					if rel.String() == failing {
+						return errors.New("failed " + failing)
+					}
+				}
+				return nil
+			}
+
+			err := o.forEachRelease(releases, action)
+
+			switch {
+			case len(tc.failing) == 0:
+				require.NoError(t, err)
+			case tc.inClones:
+				for _, failing := range tc.failing {
+					assert.ErrorContains(t, err, "failed "+failing)
+				}
+			default:
+				assert.EqualError(t, err, "failed "+tc.failing[0])
+			}
+			assert.Len(t, dirs, tc.processed)
+			assert.LessOrEqual(t, maxActive, tc.concurrency)
+			if !tc.inClones {
+				for _, dir := range dirs {
+					assert.Equal(t, o.Project.Path, dir)
+				}
+				return
+			}
+			assert.Equal(t, tc.concurrency, maxActive)
+			for _, dir := range dirs {
+				assert.NotEqual(t, o.Project.Path, dir)
+				_, serr := os.Stat(dir)
+				require.ErrorIs(t, serr, os.ErrNotExist, "the clone is removed")
+			}
+		})
+	}
+}
+
+func TestRebase(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	for _, tc := range []struct {
+		pth, from, want string
+	}{
+		{pth: "", from: "/project", want: "/clone"},
+		{pth: "/project", from: "/project", want: "/clone"},
+		{pth: "/project/images", from: "/project", want: "/clone/images"},
+		{pth: "/elsewhere", from: "/project", want: "/elsewhere"},
+		{pth: "images", from: ".", want: "/clone/images"},
+		{pth: path.Join(wd, "images"), from: ".", want: "/clone/images"},
+		{pth: "images", from: wd, want: "/clone/images"},
+	} {
+		assert.Equal(t, tc.want, rebase(tc.pth, tc.from, "/clone"), tc)
+	}
+}
@@ -0,0 +1,385 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/openshift-knative/deviate/pkg/errors"
+)
+
+// ActionKind is a kind of action the sync would perform.
+type ActionKind string
+
+const (
+	// ActionCreateBranch creates a local branch out of a remote one.
+	ActionCreateBranch ActionKind = "create-branch"
+	// ActionResetBranch resets a local branch to a remote one.
+	ActionResetBranch ActionKind = "reset-branch"
+	// ActionMerge merges an upstream branch, if it has new changes.
+	ActionMerge ActionKind = "merge"
+	// ActionCommit commits changes, if there are any.
+	ActionCommit ActionKind = "commit"
+	// ActionPush pushes a reference to the downstream remote.
+	ActionPush ActionKind = "push"
+	// ActionOpenPR opens a new pull request.
+	ActionOpenPR ActionKind = "open-pr"
+	// ActionReusePR reuses already opened pull request.
+	ActionReusePR ActionKind = "reuse-pr"
+	// ActionEnsurePR opens a pull request, unless it's already opened.
+	ActionEnsurePR ActionKind = "ensure-pr"
+	// ActionClosePR closes a stale pull request.
+	ActionClosePR ActionKind = "close-pr"
+	// ActionDeleteBranch deletes a stale branch from the downstream remote.
+	ActionDeleteBranch ActionKind = "delete-branch"
+)
+
+// Action is a single intended action of the sync.
+type Action struct {
+	Kind        ActionKind `json:"kind"`
+	Release     string     `json:"release,omitempty"`
+	Ref         string     `json:"ref,omitempty"`
+	Source      string     `json:"source,omitempty"`
+	URL         string     `json:"url,omitempty"`
+	Conditional bool       `json:"conditional,omitempty"`
+	Description string     `json:"description"`
+}
+
+// Plan is an ordered list of actions the sync would perform.
+type Plan struct {
+	Upstream   string   `json:"upstream"`
+	Downstream string   `json:"downstream"`
+	DryRun     bool     `json:"dryRun"`
+	Actions    []Action `json:"actions"`
+}
+
+// WriteText writes the plan in human-readable form.
+func (p Plan) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Sync plan of %s from %s\n",
+		p.Downstream, p.Upstream); err != nil {
+		return errors.Wrap(err, ErrSyncFailed)
+	}
+	if p.DryRun {
+		if _, err := fmt.Fprintln(w, "Dry run is enabled, "+
+			"nothing will be pushed"); err != nil {
+			return errors.Wrap(err, ErrSyncFailed)
+		}
+	}
+	for i, a := range p.Actions {
+		desc := a.Description
+		if a.Conditional {
+			desc += " (if changed)"
+		}
+		if _, err := fmt.Fprintf(w, "%3d. %-13s %s\n",
+			i+1, a.Kind, desc); err != nil {
+			return errors.Wrap(err, ErrSyncFailed)
+		}
+	}
+	return nil
+}
+
+// Plan computes the actions the sync would perform, without changing
+// anything in the local repository nor the remotes.
+func (o Operation) Plan() (*Plan, error) {
+	p := planner{Operation: o, plan: &Plan{
+		Upstream:   o.Upstream,
+		Downstream: o.Downstream,
+		DryRun:     o.DryRun,
+		Actions:    make([]Action, 0),
+	}}
+	if err := runSteps([]step{
+		p.mirrorReleases,
+		p.syncTags,
+		p.syncReleaseNext,
+		p.triggerCI,
+		p.releaseNextPR,
+		p.cleanup,
+	}); err != nil {
+		return nil, err
+	}
+	return p.plan, nil
+}
+
+type planner struct {
+	Operation
+	plan *Plan
+
+	// missing releases are excluded from the re-sync.
+	missing []release
+}
+
+func (p *planner) add(a Action) {
+	p.plan.Actions = append(p.plan.Actions, a)
+}
+
+// withRelease returns the planner with the configuration overrides of the
+// release applied. The actions are added to the same plan.
+func (p *planner) withRelease(rel release) (*planner, error) {
+	op, err := p.Operation.withRelease(rel)
+	if err != nil {
+		return nil, err
+	}
+	return &planner{Operation: op, plan: p.plan, missing: p.missing}, nil
+}
+
+func (p *planner) mirrorReleases() error {
+	missing, err := p.findMissingDownstreamReleases()
+	if err != nil {
+		return err
+	}
+	p.missing = missing
+	for _, rel := range missing {
+		if err = p.mirrorRelease(rel); err != nil {
+			return err
+		}
+	}
+	return p.resyncReleases()
+}
+
+func (p *planner) mirrorRelease(rel release) error {
+	p, err := p.withRelease(rel)
+	if err != nil {
+		return err
+	}
+	upstreamBranch, downstreamBranch, err := releaseBranches(p.Config, rel)
+	if err != nil {
+		return err
+	}
+	p.add(Action{
+		Kind:    ActionCreateBranch,
+		Release: rel.String(),
+		Ref:     downstreamBranch,
+		Source:  "upstream/" + upstreamBranch,
+		Description: fmt.Sprintf("Create release %s as %s from upstream/%s",
+			rel.String(), downstreamBranch, upstreamBranch),
+	})
+	p.addForkFiles(rel, downstreamBranch)
+	p.add(p.push(rel, plumbing.NewBranchReferenceName(downstreamBranch)))
+	return nil
+}
+
+func (p *planner) resyncReleases() error {
+	if !p.Enabled {
+		return nil
+	}
+	releases, err := p.releasesToResync(p.missing)
+	if err != nil {
+		return err
+	}
+	for _, rel := range releases {
+		if err = p.resyncRelease(rel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *planner) resyncRelease(rel release) error {
+	p, err := p.withRelease(rel)
+	if err != nil {
+		return err
+	}
+	upstreamBranch, downstreamBranch, err := releaseBranches(p.Config, rel)
+	if err != nil {
+		return err
+	}
+	syncBranch := p.CheckPrPrefix + downstreamBranch
+	merge := fmt.Sprintf("Merge upstream/%s into %s, and reset it to upstream/%s, "+
+		"if there are changes", upstreamBranch, syncBranch, upstreamBranch)
+	if p.OnConflict.OpenPR {
+		merge += fmt.Sprintf(", unless the merge conflicts, which are resolved "+
+			"with the %q strategy", p.OnConflict.Strategy)
+	}
+	p.add(Action{
+		Kind:    ActionCreateBranch,
+		Release: rel.String(),
+		Ref:     syncBranch,
+		Source:  "downstream/" + downstreamBranch,
+		Description: fmt.Sprintf("Create %s from downstream/%s",
+			syncBranch, downstreamBranch),
+	})
+	p.add(Action{
+		Kind:        ActionMerge,
+		Release:     rel.String(),
+		Ref:         syncBranch,
+		Source:      "upstream/" + upstreamBranch,
+		Conditional: true,
+		Description: merge,
+	})
+	if !p.DockerfileGen.Skip {
+		p.add(p.commit(rel, syncBranch, p.ImagesGenerated))
+	}
+	push := p.push(rel, plumbing.NewBranchReferenceName(syncBranch))
+	push.Conditional = true
+	p.add(push)
+	pr := p.syncReleasePR(downstreamBranch, upstreamBranch, syncBranch)
+	a := p.pullRequest(pr)
+	a.Release = rel.String()
+	a.Conditional = true
+	p.add(a)
+	return nil
+}
+
+func (p *planner) syncTags() error {
+	refName := plumbing.NewTagReferenceName(p.RefSpec)
+	p.add(p.push(nil, refName))
+	return nil
+}
+
+func (p *planner) syncReleaseNext() error {
+	rel := nextRelease{}
+	p, err := p.withRelease(rel)
+	if err != nil {
+		return err
+	}
+	p.add(Action{
+		Kind:    ActionResetBranch,
+		Release: rel.String(),
+		Ref:     p.ReleaseNext,
+		Source:  "upstream/" + p.Main,
+		Description: fmt.Sprintf("Reset %s to upstream/%s",
+			p.ReleaseNext, p.Main),
+	})
+	p.addForkFiles(rel, p.ReleaseNext)
+	p.add(p.push(rel, plumbing.NewBranchReferenceName(p.ReleaseNext)))
+	return nil
+}
+
+func (p *planner) triggerCI() error {
+	rel := nextRelease{}
+	p, err := p.withRelease(rel)
+	if err != nil {
+		return err
+	}
+	if p.SkipCheckPr {
+		return nil
+	}
+	branch := p.CheckPrPrefix + p.ReleaseNext
+	p.add(Action{
+		Kind:    ActionCreateBranch,
+		Release: rel.String(),
+		Ref:     branch,
+		Source:  "downstream/" + p.ReleaseNext,
+		Description: fmt.Sprintf("Create %s from downstream/%s",
+			branch, p.ReleaseNext),
+	})
+	// The CI trigger always changes the branch, so it's always committed.
+	commit := p.commit(rel, branch, p.triggerCIMessage())
+	commit.Conditional = false
+	p.add(commit)
+	p.add(p.push(rel, plumbing.NewBranchReferenceName(branch)))
+	return nil
+}
+
+func (p *planner) releaseNextPR() error {
+	p, err := p.withRelease(nextRelease{})
+	if err != nil {
+		return err
+	}
+	a := p.pullRequest(p.syncReleaseNextPR())
+	a.Release = nextRelease{}.String()
+	p.add(a)
+	return nil
+}
+
+func (p *planner) cleanup() error {
+	if !p.Cleanup {
+		return nil
+	}
+	stale, err := p.staleSyncBranches()
+	if err != nil {
+		return err
+	}
+	for _, branch := range stale {
+		pr := p.stalePR(branch)
+		if url, aerr := pr.active(); aerr == nil {
+			p.add(Action{
+				Kind:        ActionClosePR,
+				Ref:         pr.base,
+				Source:      pr.head,
+				URL:         *url,
+				Description: fmt.Sprintf("Close the stale PR of %s into %s: %s", pr.head, pr.base, *url),
+			})
+		}
+		a := Action{
+			Kind:        ActionDeleteBranch,
+			Ref:         plumbing.NewBranchReferenceName(branch).String(),
+			Description: fmt.Sprintf("Delete stale %s from downstream", branch),
+		}
+		if p.DryRun {
+			a.Description += ", skipped because of dry run"
+		}
+		p.add(a)
+	}
+	return nil
+}
+
+func (p *planner) addForkFiles(rel release, branch string) {
+	p.add(p.commit(rel, branch, p.ApplyForkFiles))
+	if !p.DockerfileGen.Skip {
+		p.add(p.commit(rel, branch, p.ImagesGenerated))
+	}
+	p.add(p.commit(rel, branch, applyPatchesMessage))
+}
+
+func (p *planner) commit(rel release, branch, message string) Action {
+	return Action{
+		Kind:        ActionCommit,
+		Release:     rel.String(),
+		Ref:         branch,
+		Conditional: true,
+		Description: fmt.Sprintf("Commit %q onto %s", message, branch),
+	}
+}
+
+func (p *planner) push(rel release, refName plumbing.ReferenceName) Action {
+	a := Action{
+		Kind:        ActionPush,
+		Ref:         refName.String(),
+		Description: fmt.Sprintf("Push %s to downstream", refName),
+	}
+	if ownedRef(p.Config, refName) {
+		a.Description = fmt.Sprintf("Force push %s to downstream", refName)
+	}
+	if rel != nil {
+		a.Release = rel.String()
+	}
+	if p.DryRun {
+		a.Description += ", skipped because of dry run"
+	}
+	return a
+}
+
+func (p *planner) pullRequest(pr createPR) Action {
+	a := Action{
+		Ref:    pr.base,
+		Source: pr.head,
+	}
+	url, err := pr.active()
+	switch {
+	case err == nil:
+		a.Kind = ActionReusePR
+		a.URL = *url
+		a.Description = fmt.Sprintf("Reuse, and refresh the PR of %s into %s: %s",
+			pr.head, pr.base, *url)
+	case errors.Is(err, errPrNotFound):
+		a.Kind = ActionOpenPR
+		a.Description = fmt.Sprintf("Open a PR of %s into %s: %q",
+			pr.head, pr.base, pr.title)
+		if pr.settings.Draft {
+			a.Description += ", as a draft"
+		}
+		if pr.settings.AutoMerge != "" {
+			a.Description += fmt.Sprintf(", with %s auto-merge",
+				pr.settings.AutoMerge)
+		}
+		if p.DryRun {
+			a.Description += ", skipped because of dry run"
+		}
+	default:
+		a.Kind = ActionEnsurePR
+		a.Description = fmt.Sprintf("Open a PR of %s into %s, "+
+			"unless already opened (can't check: %v)", pr.head, pr.base, err)
+	}
+	return a
+}
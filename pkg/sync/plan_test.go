@@ -0,0 +1,145 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	gitv5 "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/openshift-knative/deviate/pkg/provenance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOperation_Plan compares the plan with the actions of the real sync. On
+// the second sync, nothing has changed upstream, and the sync PR of the
+// release is merged, so only the unconditional actions are expected.
+func TestOperation_Plan(t *testing.T) {
+	f := newFixture(t)
+	f.commit("release-0.9", map[string]string{"a.txt": "0.9\n"})
+	f.forkCommit("release-0.9", map[string]string{"fork.txt": "fork\n"})
+	f.commit("release-0.9", map[string]string{"a.txt": "0.9.1\n"})
+	f.commit("release-1.0", map[string]string{"a.txt": "1.0\n"})
+	gh := newFakeGitHub(t)
+	for i, runID := range []string{"first", "second"} {
+		o := f.operation(gh.config() + "resyncReleases:\n  enabled: true\n")
+		o.RunID = runID
+
+		plan, err := o.Plan()
+		require.NoError(t, err)
+		before := f.downstreamBranches()
+		require.NoError(t, o.Run())
+
+		done := f.doneBy(o.RunID, before)
+		for _, req := range gh.take() {
+			if req.Kind == "pulls" && req.Number == 0 {
+				done = append(done, fmt.Sprintf("%s %s into %s",
+					ActionOpenPR, req.Body["head"], req.Body["base"]))
+			}
+		}
+		planned := make([]string, 0, len(plan.Actions))
+		unconditional := make([]string, 0, len(plan.Actions))
+		for _, a := range plan.Actions {
+			key := actionKey(a)
+			if key == "" {
+				continue
+			}
+			planned = append(planned, key)
+			if !a.Conditional {
+				unconditional = append(unconditional, key)
+			}
+		}
+		if i == 0 {
+			for _, key := range unconditional {
+				assert.Contains(t, done, key, "the run did: %+q", done)
+			}
+			assert.Contains(t, done, "push refs/heads/ci/release-0.9")
+		}
+		for _, key := range done {
+			if i == 0 {
+				assert.Contains(t, planned, key, "the plan is: %+q", planned)
+			} else {
+				assert.Contains(t, unconditional, key,
+					"the unconditional actions are: %+q", unconditional)
+			}
+		}
+		f.mergeDownstream("ci/release-0.9", "release-0.9")
+	}
+}
+
+// mergeDownstream fast-forwards the downstream branch, like a merge of the PR.
+func (f *fixture) mergeDownstream(head, base string) {
+	f.tb.Helper()
+	repo, err := gitv5.PlainOpen(f.downstream)
+	require.NoError(f.tb, err)
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(head), true)
+	require.NoError(f.tb, err)
+	require.NoError(f.tb, repo.Storer.SetReference(plumbing.NewHashReference(
+		plumbing.NewBranchReferenceName(base), ref.Hash())))
+}
+
+// actionKey identifies the action, by its outcome observed in the
+// downstream, or none, if the action has no such outcome.
+func actionKey(a Action) string {
+	switch a.Kind {
+	case ActionCommit:
+		return a.Description
+	case ActionMerge:
+		return fmt.Sprintf("%s onto %s", a.Kind, a.Ref)
+	case ActionPush:
+		if strings.HasPrefix(a.Ref, "refs/heads/") {
+			return fmt.Sprintf("%s %s", a.Kind, a.Ref)
+		}
+	case ActionOpenPR:
+		return fmt.Sprintf("%s %s into %s", a.Kind, a.Source, a.Ref)
+	}
+	return ""
+}
+
+func (f *fixture) downstreamBranches() map[plumbing.ReferenceName]plumbing.Hash {
+	f.tb.Helper()
+	repo, err := gitv5.PlainOpen(f.downstream)
+	require.NoError(f.tb, err)
+	refs, err := repo.Branches()
+	require.NoError(f.tb, err)
+	branches := make(map[plumbing.ReferenceName]plumbing.Hash)
+	require.NoError(f.tb, refs.ForEach(func(ref *plumbing.Reference) error {
+		branches[ref.Name()] = ref.Hash()
+		return nil
+	}))
+	return branches
+}
+
+// doneBy returns the keys of the pushes of the downstream branches, changed
+// since before, and of the commits on them, made by the run.
+func (f *fixture) doneBy(runID string, before map[plumbing.ReferenceName]plumbing.Hash) []string {
+	f.tb.Helper()
+	repo, err := gitv5.PlainOpen(f.downstream)
+	require.NoError(f.tb, err)
+	done := make([]string, 0)
+	for name, hash := range f.downstreamBranches() {
+		if before[name] == hash {
+			continue
+		}
+		done = append(done, actionKey(Action{Kind: ActionPush, Ref: name.String()}))
+		commits, lerr := repo.Log(&gitv5.LogOptions{From: hash})
+		require.NoError(f.tb, lerr)
+		require.NoError(f.tb, commits.ForEach(func(c *object.Commit) error {
+			p, ok := provenance.Parse(c.Message)
+			if !ok || p.RunID != runID {
+				return nil
+			}
+			a := Action{Kind: ActionCommit, Ref: name.Short()}
+			if p.Step == stepMerge {
+				a.Kind = ActionMerge
+			}
+			subject, _, _ := strings.Cut(c.Message, "\n")
+			a.Description = fmt.Sprintf("Commit %q onto %s", subject, a.Ref)
+			done = append(done, actionKey(a))
+			return nil
+		}))
+	}
+	return done
+}
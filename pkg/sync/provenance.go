@@ -0,0 +1,44 @@
+package sync
+
+import (
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/openshift-knative/deviate/pkg/metadata"
+	"github.com/openshift-knative/deviate/pkg/provenance"
+)
+
+// Steps of the sync, recorded in the provenance of the commits.
+const (
+	stepForkFiles = "fork-files"
+	stepImages    = "images"
+	stepPatches   = "patches"
+	stepTriggerCI = "trigger-ci"
+	stepMerge     = "merge"
+)
+
+// commitProvenance returns the provenance of a commit made by the step. The
+// upstream commit is resolved when the provenance is taken, as the upstream
+// is fetched by the steps.
+func (o Operation) commitProvenance(kind string) provenance.Provenance {
+	p := provenance.Provenance{
+		Version: metadata.Version,
+		Step:    kind,
+		RunID:   o.RunID,
+	}
+	if o.upstreamRef == "" {
+		return p
+	}
+	p.UpstreamRef = plumbing.NewBranchReferenceName(o.upstreamRef).String()
+	ref := plumbing.NewRemoteReferenceName("upstream", o.upstreamRef)
+	if hash, err := o.Repository.ResolveRevision(plumbing.Revision(ref)); err == nil {
+		p.UpstreamCommit = hash.String()
+	}
+	return p
+}
+
+// mergeMessage decorates the message of the upstream merge, with the
+// provenance of the upstream commit that was actually merged.
+func (o Operation) mergeMessage(message string, merged plumbing.Hash) string {
+	p := o.commitProvenance(stepMerge)
+	p.UpstreamCommit = merged.String()
+	return p.Append(message)
+}
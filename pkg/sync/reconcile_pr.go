@@ -0,0 +1,161 @@
+package sync
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/forge"
+	"github.com/openshift-knative/deviate/pkg/log/color"
+)
+
+const (
+	changelogLimit  = 50
+	changelogHeader = "### Changes"
+)
+
+// headMarkerRe matches the hidden marker, that records the head commit of the
+// PR, as of the last sync.
+var headMarkerRe = regexp.MustCompile(`<!-- deviate:head=([0-9a-f]{40}) -->`)
+
+type prChangelog struct {
+	body    string
+	head    string
+	commits []*object.Commit
+}
+
+// reconcile refreshes the title, the body, and the labels of the existing PR,
+// and comments on it, summarizing the changes. The body is rebuilt out of all
+// the commits of the PR, while the comment lists the commits pushed since the
+// last sync. Nothing is done, if the PR is up-to-date.
+func (c createPR) reconcile(existing *forge.Request) error {
+	cl := c.changelog(c.baseRef())
+	update := forge.Update{}
+	changes := make([]string, 0)
+	if existing.Title != c.title {
+		update.Title = &c.title
+		changes = append(changes, "- Title updated")
+	}
+	if strings.TrimSpace(existing.Body) != strings.TrimSpace(cl.body) {
+		update.Body = &cl.body
+		changes = append(changes, "- Description updated")
+	}
+	missing := make([]string, 0)
+	for _, l := range c.allLabels() {
+		if !slices.Contains(existing.Labels, l) && !slices.Contains(missing, l) {
+			missing = append(missing, l)
+		}
+	}
+	if len(missing) > 0 {
+		changes = append(changes, "- Labels added: "+strings.Join(missing, ", "))
+	}
+	if len(changes) == 0 {
+		c.Println("- The PR is up-to-date")
+		return nil
+	}
+	if commits := c.newCommits(existing.Body, cl); len(commits) > 0 {
+		changes = append(changes, fmt.Sprintf("- %d new commit(s):\n%s",
+			len(commits), commitList(commits, "  ")))
+	}
+	summary := "This PR was refreshed by the sync:\n\n" + strings.Join(changes, "\n")
+	if c.DryRun {
+		c.Println(color.Yellow("- Skipping the PR update, because of dry run"))
+		c.Println(summary)
+		return nil
+	}
+	return c.applyReconcile(existing.Number, update, missing, summary)
+}
+
+// newCommits lists the commits of the changelog, pushed since the last sync,
+// as recorded by the head marker of the PR body. All the commits are new, if
+// the body has no marker.
+func (c createPR) newCommits(body string, cl prChangelog) []*object.Commit {
+	m := headMarkerRe.FindStringSubmatch(body)
+	if m == nil || cl.head == "" {
+		return cl.commits
+	}
+	commits, err := c.Changelog(m[1], cl.head, changelogLimit)
+	if err != nil {
+		c.Println(color.Yellow(fmt.Sprintf("- Skipping new commits: %v", err)))
+		return nil
+	}
+	return commits
+}
+
+func (c createPR) applyReconcile(number int, update forge.Update, labels []string, summary string) error {
+	f, err := c.forge()
+	if err != nil {
+		return err
+	}
+	if update.Title != nil || update.Body != nil {
+		if _, err = f.Update(c.Context, number, update); err != nil {
+			return errors.Wrap(err, ErrSyncFailed)
+		}
+	}
+	if err = f.AddLabels(c.Context, number, labels...); err != nil {
+		return errors.Wrap(err, ErrSyncFailed)
+	}
+	c.Println("- PR refreshed")
+	return errors.Wrap(f.Comment(c.Context, number, summary), ErrSyncFailed)
+}
+
+// changelog renders the PR body with the commits of the head branch, not
+// reachable from the since revision. The changelog is omitted, if the head
+// branch can't be resolved locally.
+func (c createPR) changelog(since string) prChangelog {
+	head, err := c.headRevision()
+	if err != nil {
+		c.Println(color.Yellow(fmt.Sprintf("- Skipping changelog: %v", err)))
+		return prChangelog{body: c.body}
+	}
+	marker := fmt.Sprintf("<!-- deviate:head=%s -->", head)
+	commits, err := c.Changelog(since, head, changelogLimit)
+	if err != nil {
+		c.Println(color.Yellow(fmt.Sprintf("- Skipping changelog: %v", err)))
+		return prChangelog{body: c.body + "\n\n" + marker, head: head}
+	}
+	body := c.body
+	if len(commits) > 0 {
+		body += "\n\n" + changelogHeader + "\n\n" + commitList(commits, "")
+	}
+	return prChangelog{body: body + "\n\n" + marker, head: head, commits: commits}
+}
+
+// headRevision returns the hash of the head branch, as pushed to downstream,
+// or as it's known locally.
+func (c createPR) headRevision() (string, error) {
+	var errs []error
+	for _, ref := range []plumbing.ReferenceName{
+		plumbing.NewRemoteReferenceName("downstream", c.head),
+		plumbing.NewBranchReferenceName(c.head),
+	} {
+		hash, err := c.Repository.ResolveRevision(plumbing.Revision(ref))
+		if err == nil {
+			return hash.String(), nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", ref, err))
+	}
+	return "", errors.Join(errs...)
+}
+
+func (c createPR) baseRef() string {
+	return plumbing.NewRemoteReferenceName("downstream", c.base).String()
+}
+
+func (c createPR) allLabels() []string {
+	return append(append([]string{}, c.SyncLabels...), c.labels...)
+}
+
+func commitList(commits []*object.Commit, indent string) string {
+	var sb strings.Builder
+	for _, commit := range commits {
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		sb.WriteString(fmt.Sprintf("%s- %s %s\n", indent,
+			commit.Hash.String()[:7], subject))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,74 @@
+package sync
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/openshift-knative/deviate/pkg/config/git"
+	"github.com/openshift-knative/deviate/pkg/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePR_Reconcile(t *testing.T) {
+	f := newFixture(t)
+	f.commit("release-1.0", map[string]string{"a.txt": "a\n"})
+	f.push("release-1.0")
+	// The sync branch is made of the commits on top of the release.
+	require.NoError(t, f.upstream.Storer.SetReference(plumbing.NewHashReference(
+		"refs/heads/fork/ci/release-1.0", f.head("release-1.0"))))
+	gh := newFakeGitHub(t)
+	gh.pulls = append(gh.pulls, &github.PullRequest{
+		Number: 1,
+		State:  "open",
+		Title:  "outdated",
+		Body:   "outdated",
+		Head:   github.Branch{Ref: "ci/release-1.0"},
+		Base:   github.Branch{Ref: "release-1.0"},
+	})
+	o := f.operation(gh.config())
+	pr := o.newPR("Sync release-1.0", "Sync body", "release-1.0", "ci/release-1.0")
+	sync := func(files map[string]string) plumbing.Hash {
+		t.Helper()
+		hash := f.commit("fork/ci/release-1.0", files)
+		f.pushAs("fork/ci/release-1.0", "ci/release-1.0")
+		require.NoError(t, o.Fetch(git.Remote{Name: "downstream", URL: o.Downstream}))
+		existing, err := pr.find()
+		require.NoError(t, err)
+		require.NoError(t, pr.reconcile(existing))
+		return hash
+	}
+
+	first := sync(map[string]string{"b.txt": "b\n"})
+
+	requests := gh.take()
+	require.Len(t, requests, 3)
+	assert.Equal(t, http.MethodPatch, requests[0].Method)
+	assert.Equal(t, "Sync release-1.0", requests[0].Body["title"])
+	assert.Contains(t, requests[0].Body["body"], first.String()[:7])
+	assert.Equal(t, "labels", requests[1].Kind)
+	assert.Equal(t, "comments", requests[2].Kind)
+	assert.Contains(t, requests[2].Body["body"], "1 new commit(s)")
+
+	second := sync(map[string]string{"c.txt": "c\n"})
+
+	requests = gh.take()
+	require.Len(t, requests, 2)
+	assert.Equal(t, http.MethodPatch, requests[0].Method)
+	assert.Nil(t, requests[0].Body["title"])
+	// The description lists all the commits of the PR.
+	assert.Contains(t, requests[0].Body["body"], first.String()[:7])
+	assert.Contains(t, requests[0].Body["body"], second.String()[:7])
+	assert.Equal(t, "comments", requests[1].Kind)
+	// The comment lists only the commits pushed since the last sync.
+	assert.Contains(t, requests[1].Body["body"], "1 new commit(s)")
+	assert.Contains(t, requests[1].Body["body"], second.String()[:7])
+	assert.NotContains(t, requests[1].Body["body"], first.String()[:7])
+
+	existing, err := pr.find()
+	require.NoError(t, err)
+	require.NoError(t, pr.reconcile(existing))
+
+	assert.Empty(t, gh.take())
+}
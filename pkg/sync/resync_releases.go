@@ -2,10 +2,13 @@ package sync
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	gitv5 "github.com/go-git/go-git/v5"
 	"github.com/openshift-knative/deviate/pkg/config/git"
 	"github.com/openshift-knative/deviate/pkg/errors"
+	pkggit "github.com/openshift-knative/deviate/pkg/git"
 	"github.com/openshift-knative/deviate/pkg/log/color"
 )
 
@@ -13,24 +16,16 @@ func (o Operation) resyncReleases(excluded []release) error {
 	if !o.Enabled {
 		return nil
 	}
-	releases, err := o.listReleases(true)
+	releases, err := o.releasesToResync(excluded)
 	if err != nil {
-		return errors.Wrap(err, ErrSyncFailed)
-	}
-	releases = filterOutExcluded(releases, excluded)
-	idx := len(releases) - o.NumberOf
-	if idx > 0 {
-		releases = releases[idx:]
+		return err
 	}
 
 	if len(releases) > 0 {
 		o.Printf("Re-syncing releases: %s\n",
 			color.Blue(fmt.Sprintf("%+q", releases)))
-		for _, rel := range releases {
-			err = o.resyncRelease(rel)
-			if err != nil {
-				return err
-			}
+		if err = o.forEachRelease(releases, Operation.resyncRelease); err != nil {
+			return err
 		}
 	} else {
 		o.Println("No releases to re-sync")
@@ -38,9 +33,34 @@ func (o Operation) resyncReleases(excluded []release) error {
 	return nil
 }
 
+func (o Operation) releasesToResync(excluded []release) ([]release, error) {
+	releases, err := o.listReleases(true)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrSyncFailed)
+	}
+	releases = filterOutExcluded(releases, excluded)
+	releases, err = o.maintainedReleases(releases, o.EndOfLife)
+	if err != nil {
+		return nil, err
+	}
+	idx := len(releases) - o.NumberOf
+	if idx > 0 {
+		releases = releases[idx:]
+	}
+	return releases, nil
+}
+
 func (o Operation) resyncRelease(rel release) error {
+	o, err := o.withRelease(rel)
+	if err != nil {
+		return err
+	}
 	rr := resyncRelease{o, rel}
-	return rr.run()
+	upstreamBranch, _, err := releaseBranches(o.Config, rel)
+	if err != nil {
+		return err
+	}
+	return o.journaled("resync/"+rel.String(), upstreamBranch, rr.run)()
 }
 
 type resyncRelease struct {
@@ -49,15 +69,12 @@ type resyncRelease struct {
 }
 
 func (r resyncRelease) run() error {
-	upstreamBranch, err := r.rel.Name(r.ReleaseTemplates.Upstream)
+	upstreamBranch, downstreamBranch, err := releaseBranches(r.Config, r.rel)
 	if err != nil {
-		return errors.Wrap(err, ErrSyncFailed)
-	}
-	downstreamBranch, err := r.rel.Name(r.ReleaseTemplates.Downstream)
-	if err != nil {
-		return errors.Wrap(err, ErrSyncFailed)
+		return err
 	}
 	syncBranch := r.CheckPrPrefix + downstreamBranch
+	r.upstreamRef = upstreamBranch
 	r.Printf("Re-syncing release: %s\n", color.Blue(r.rel.String()))
 	downstreamRemote := git.Remote{
 		Name: "downstream",
@@ -72,24 +89,25 @@ func (r resyncRelease) run() error {
 		changes = true
 		return nil
 	}
+	var conflicts *pkggit.MergeConflicts
 	return runSteps([]step{
 		r.checkoutAs(downstreamRemote, downstreamBranch, syncBranch),
-		r.mergeUpstream(upstreamBranch, []step{
+		r.mergeUpstream(upstreamBranch, &conflicts, []step{
 			r.checkoutAs(upstreamRemote, upstreamBranch, syncBranch),
 			changesDetected,
 		}),
 		r.generateImages(r.rel),
-		r.commitChanges(r.ImagesGenerated, changesDetected),
+		r.commitChanges(stepImages, r.ImagesGenerated, changesDetected),
 		func() (err error) {
 			defer func() {
 				err = errors.Join(err, r.deleteBranch(syncBranch))
 			}()
-			if !changes {
+			if !changes && conflicts == nil {
 				return nil
 			}
 			err = multiStep{
 				r.pushBranch(syncBranch, skipDeleteOnPush),
-				r.createSyncReleasePR(downstreamBranch, upstreamBranch, syncBranch),
+				r.createSyncReleasePR(downstreamBranch, upstreamBranch, syncBranch, conflicts),
 			}.runSteps()
 			return
 		},
@@ -103,32 +121,94 @@ func (r resyncRelease) checkoutAs(remote git.Remote, targetBranch, branch string
 	}
 }
 
-func (r resyncRelease) mergeUpstream(upstreamBranch string, onChanges []step) step {
+// mergeUpstream merges the upstream branch. When the merge conflicts were
+// committed, they are stored for the PR to list them, otherwise the onChanges
+// steps are run, if there are any upstream changes, even if they couldn't be
+// merged.
+func (r resyncRelease) mergeUpstream(
+	upstreamBranch string, conflicts **pkggit.MergeConflicts, onChanges []step,
+) step {
 	upstream := git.Remote{
 		Name: "upstream",
 		URL:  r.Upstream,
 	}
 	return func() error {
-		err := r.Merge(&upstream, upstreamBranch)
+		opts := []git.MergeOption{
+			git.WithMessage(r.mergeMessage),
+		}
+		if r.OnConflict.OpenPR {
+			opts = append(opts, git.WithMergeStrategy(r.OnConflict.Strategy))
+		}
+		err := r.Merge(&upstream, upstreamBranch, opts...)
 		if errors.Is(err, gitv5.NoErrAlreadyUpToDate) {
 			r.Println("- no changes detected")
 			return nil
 		}
+		var mc *pkggit.MergeConflicts
+		if errors.As(err, &mc) {
+			r.Printf("- merge conflicts with %s:\n%s", mc.Target, mc.Details())
+			if mc.Committed {
+				r.Printf("- conflicts committed, resolved with the %q strategy\n",
+					r.OnConflict.Strategy)
+				*conflicts = mc
+				return nil
+			}
+		} else if err != nil {
+			r.Println("- merge failed:", err)
+		}
 		r.Println("- changes detected")
 		return runSteps(onChanges)
 	}
 }
 
-func (r resyncRelease) createSyncReleasePR(downstreamBranch, upstreamBranch, syncBranch string) step {
+func (r resyncRelease) createSyncReleasePR(
+	downstreamBranch, upstreamBranch, syncBranch string,
+	conflicts *pkggit.MergeConflicts,
+) step {
 	return func() error {
-		title := fmt.Sprintf(
-			r.TriggerCI,
-			downstreamBranch, upstreamBranch)
-		body := fmt.Sprintf(
-			r.TriggerCIBody,
-			downstreamBranch, upstreamBranch)
-		return r.createPR(title, body, downstreamBranch, syncBranch)
+		pr := r.syncReleasePR(downstreamBranch, upstreamBranch, syncBranch)
+		if conflicts == nil {
+			return r.createPR(pr)
+		}
+		pr.body += "\n\n" + r.conflictsDescription(upstreamBranch, conflicts)
+		if r.OnConflict.Label != "" {
+			pr.labels = append(pr.labels, r.OnConflict.Label)
+		}
+		return r.createPR(pr)
+	}
+}
+
+func (r resyncRelease) conflictsDescription(
+	upstreamBranch string, conflicts *pkggit.MergeConflicts,
+) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(r.Messages.MergeConflicts,
+		upstreamBranch, r.OnConflict.Strategy))
+	sb.WriteString("\n\n")
+	for _, f := range conflicts.Files {
+		sb.WriteString(fmt.Sprintf("- `%s` - %s", f.Path, f.Reason))
+		if len(f.Hunks) > 0 {
+			lines := make([]string, 0, len(f.Hunks))
+			for _, h := range f.Hunks {
+				lines = append(lines, strconv.Itoa(h.Line))
+			}
+			sb.WriteString(" (lines: " + strings.Join(lines, ", ") + ")")
+		}
+		sb.WriteString("\n")
 	}
+	return sb.String()
+}
+
+func (o Operation) syncReleasePR(downstreamBranch, upstreamBranch, syncBranch string) createPR {
+	title := fmt.Sprintf(
+		o.TriggerCI,
+		downstreamBranch, upstreamBranch)
+	body := fmt.Sprintf(
+		o.TriggerCIBody,
+		downstreamBranch, upstreamBranch)
+	pr := o.newPR(title, body, downstreamBranch, syncBranch)
+	pr.settings = o.PullRequests.Releases
+	return pr
 }
 
 func (r resyncRelease) deleteBranch(branch string) error {
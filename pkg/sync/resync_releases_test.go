@@ -0,0 +1,171 @@
+package sync
+
+import (
+	"path"
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/config/git"
+	pkggit "github.com/openshift-knative/deviate/pkg/git"
+	"github.com/openshift-knative/deviate/pkg/provenance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperation_ReleasesToResync(t *testing.T) {
+	tcs := []struct {
+		name     string
+		config   string
+		excluded []string
+		resync   []string
+		missing  []string
+	}{{
+		name:    "all",
+		resync:  []string{"0.9", "1.0", "1.1", "1.2", "1.3"},
+		missing: []string{"0.9", "1.0", "1.1", "1.2", "1.3"},
+	}, {
+		name:    "minimum release",
+		config:  "branches:\n  minimumRelease: \"1.1\"\n",
+		resync:  []string{"1.1", "1.2", "1.3"},
+		missing: []string{"1.1", "1.2", "1.3"},
+	}, {
+		name:    "skip releases",
+		config:  "branches:\n  skipReleases: [\"0.9\", \"1.2\"]\n",
+		resync:  []string{"1.0", "1.1", "1.3"},
+		missing: []string{"1.0", "1.1", "1.3"},
+	}, {
+		name:    "end of life",
+		config:  "branches:\n  endOfLife: [\"0.9\", \"1.0\"]\n",
+		resync:  []string{"1.1", "1.2", "1.3"},
+		missing: []string{"0.9", "1.0", "1.1", "1.2", "1.3"},
+	}, {
+		name:    "number of",
+		config:  "resyncReleases:\n  numberOf: 2\n",
+		resync:  []string{"1.2", "1.3"},
+		missing: []string{"0.9", "1.0", "1.1", "1.2", "1.3"},
+	}, {
+		name:     "excluded",
+		excluded: []string{"1.3"},
+		resync:   []string{"0.9", "1.0", "1.1", "1.2"},
+		missing:  []string{"0.9", "1.0", "1.1", "1.2", "1.3"},
+	}, {
+		name: "number of the maintained releases",
+		config: "branches:\n  skipReleases: [\"1.3\"]\n  endOfLife: [\"1.2\"]\n" +
+			"resyncReleases:\n  numberOf: 2\n",
+		excluded: []string{"1.1"},
+		resync:   []string{"0.9", "1.0"},
+		missing:  []string{"0.9", "1.0", "1.1", "1.2"},
+	}}
+	f := newFixture(t)
+	for _, version := range []string{"0.9", "1.0", "1.1", "1.2", "1.3"} {
+		f.commit("release-"+version, map[string]string{"a.txt": version + "\n"})
+	}
+	versions := func(releases []release) []string {
+		result := make([]string, 0, len(releases))
+		for _, rel := range releases {
+			result = append(result, rel.String())
+		}
+		return result
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			o := f.operation(tc.config)
+			excluded := make([]release, 0, len(tc.excluded))
+			for _, version := range tc.excluded {
+				excluded = append(excluded, parseRelease(t, version))
+			}
+
+			resync, err := o.releasesToResync(excluded)
+			require.NoError(t, err)
+			missing, err := o.findMissingDownstreamReleases()
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.resync, versions(resync))
+			assert.Equal(t, tc.missing, versions(missing))
+		})
+	}
+}
+
+func TestResyncRelease_MergeUpstream(t *testing.T) {
+	const base = "one\ntwo\nthree\n"
+	tcs := []struct {
+		name       string
+		fork       map[string]string
+		upstream   map[string]string
+		onConflict string
+		changes    bool
+		conflicts  bool
+	}{{
+		name:     "clean merge",
+		fork:     map[string]string{"fork.txt": "fork\n"},
+		upstream: map[string]string{"a.txt": "one\ntwo\nTHREE\n"},
+		changes:  true,
+	}, {
+		name: "up to date",
+		fork: map[string]string{"fork.txt": "fork\n"},
+	}, {
+		name:     "conflicts",
+		fork:     map[string]string{"a.txt": "one\n2\nthree\n"},
+		upstream: map[string]string{"a.txt": "one\nTWO\nthree\n"},
+		changes:  true,
+	}, {
+		name:       "conflicts committed",
+		fork:       map[string]string{"a.txt": "one\n2\nthree\n"},
+		upstream:   map[string]string{"a.txt": "one\nTWO\nthree\n"},
+		onConflict: "resyncReleases:\n  onConflict:\n    openPr: true\n",
+		conflicts:  true,
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.commit("release-1.0", map[string]string{"a.txt": base})
+			f.push("release-1.0")
+			forkHead := f.forkCommit("release-1.0", tc.fork)
+			upstreamHead := f.head("release-1.0")
+			if tc.upstream != nil {
+				upstreamHead = f.commit("release-1.0", tc.upstream)
+			}
+			rr := resyncRelease{f.operation(tc.onConflict), parseRelease(t, "1.0")}
+			rr.upstreamRef = "release-1.0"
+			downstream := git.Remote{Name: "downstream", URL: rr.Downstream}
+			upstream := git.Remote{Name: "upstream", URL: rr.Upstream}
+			require.NoError(t, rr.checkoutAs(downstream, "release-1.0", "ci/release-1.0")())
+			changes := false
+			var conflicts *pkggit.MergeConflicts
+
+			err := rr.mergeUpstream("release-1.0", &conflicts, []step{
+				rr.checkoutAs(upstream, "release-1.0", "ci/release-1.0"),
+				func() error {
+					changes = true
+					return nil
+				},
+			})()
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.changes, changes)
+			assert.Equal(t, tc.conflicts, conflicts != nil)
+			head, err := rr.Repository.Head()
+			require.NoError(t, err)
+			assert.Equal(t, "ci/release-1.0", head.Name().Short())
+			c := commitOf(t, rr.Operation)
+			switch {
+			case tc.changes:
+				// The sync branch is reset to the upstream one.
+				assert.Equal(t, upstreamHead, c.Hash)
+			case tc.conflicts:
+				// The merge commit, with the conflicts, is kept.
+				require.Len(t, c.ParentHashes, 2)
+				assert.Equal(t, forkHead, c.ParentHashes[0])
+				assert.Equal(t, upstreamHead, c.ParentHashes[1])
+				p, ok := provenance.Parse(c.Message)
+				require.True(t, ok)
+				assert.Equal(t, stepMerge, p.Step)
+				assert.Equal(t, upstreamHead.String(), p.UpstreamCommit)
+				for name := range tc.fork {
+					assert.FileExists(t, path.Join(rr.Project.Path, name))
+				}
+			default:
+				assert.Equal(t, forkHead, c.Hash)
+			}
+		})
+	}
+}
@@ -1,6 +1,13 @@
 package sync
 
+import "github.com/openshift-knative/deviate/pkg/config"
+
 func (o Operation) syncReleaseNext() error {
+	o, err := o.withRelease(nextRelease{})
+	if err != nil {
+		return err
+	}
+	o.upstreamRef = o.Main
 	return runSteps([]step{
 		o.resetReleaseNext,
 		o.addForkFiles(nextRelease{}),
@@ -42,6 +49,9 @@ func (n nextRelease) less(release) bool {
 	return false
 }
 
-func (n nextRelease) Tag() string {
-	return "knative-next"
+func (n nextRelease) Tag(tags config.Tags, upstream string) (string, error) {
+	return execTemplate("tag", tags.ImageNextTemplate, config.TagData{
+		Version:  n.String(),
+		Upstream: upstream,
+	})
 }
@@ -12,6 +12,11 @@ import (
 )
 
 func (o Operation) triggerCI() error {
+	o, err := o.withRelease(nextRelease{})
+	if err != nil {
+		return err
+	}
+	o.upstreamRef = o.Main
 	return triggerCI{o}.run()
 }
 
@@ -37,7 +42,7 @@ func (c triggerCI) run() error {
 	return runSteps([]step{
 		c.checkout,
 		c.addChange,
-		c.commitChanges(c.triggerCIMessage()),
+		c.commitChanges(stepTriggerCI, c.triggerCIMessage()),
 		c.pushBranch(c.CheckPrPrefix + c.ReleaseNext),
 	})
 }
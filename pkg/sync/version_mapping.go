@@ -0,0 +1,136 @@
+package sync
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/openshift-knative/deviate/pkg/config"
+	"github.com/openshift-knative/deviate/pkg/errors"
+	"github.com/openshift-knative/deviate/pkg/semver"
+)
+
+// releaseBranches returns the upstream and downstream branch names of the
+// release. Releases are kept in the upstream version space, so the release is
+// mapped to the downstream one, before naming the downstream branch.
+func releaseBranches(cfg *config.Config, rel release) (string, string, error) {
+	upstreamBranch, err := rel.Name(cfg.ReleaseTemplates.Upstream)
+	if err != nil {
+		return "", "", errors.Wrap(err, ErrSyncFailed)
+	}
+	downstreamRel, err := toDownstream(cfg.VersionMapping, rel)
+	if err != nil {
+		return "", "", err
+	}
+	downstreamBranch, err := downstreamRel.Name(cfg.ReleaseTemplates.Downstream)
+	if err != nil {
+		return "", "", errors.Wrap(err, ErrSyncFailed)
+	}
+	return upstreamBranch, downstreamBranch, nil
+}
+
+func toDownstream(mapping config.VersionMapping, rel release) (release, error) {
+	sr, ok := rel.(stdRelease)
+	if !ok {
+		return rel, nil
+	}
+	m, err := findMapping(mapping, sr.Version, func(m versionPair) semver.Version {
+		return m.up
+	})
+	if err != nil {
+		return nil, err
+	}
+	if m != nil {
+		return stdRelease{withPatch(m.down, sr.Version)}, nil
+	}
+	v := sr.Version
+	v.Major += mapping.Offset.Major
+	v.Minor += mapping.Offset.Minor
+	return stdRelease{v}, nil
+}
+
+// toUpstream translates the downstream release back into the upstream version
+// space.
+func toUpstream(mapping config.VersionMapping, rel stdRelease) (stdRelease, error) {
+	m, err := findMapping(mapping, rel.Version, func(m versionPair) semver.Version {
+		return m.down
+	})
+	if err != nil {
+		return stdRelease{}, err
+	}
+	if m != nil {
+		return stdRelease{withPatch(m.up, rel.Version)}, nil
+	}
+	v := rel.Version
+	v.Major -= mapping.Offset.Major
+	v.Minor -= mapping.Offset.Minor
+	return stdRelease{v}, nil
+}
+
+// versionPair is an explicit mapping of the upstream version to the
+// downstream one.
+type versionPair struct {
+	up, down semver.Version
+}
+
+// findMapping finds the explicit mapping, which side, given by the key, is the
+// release of the version. The most specific of the matching mappings is
+// returned, the ones specifying the patch, and the pre-release, first. The
+// mappings are visited in the order of their keys, so the result doesn't
+// depend on the map iteration order. Nil is returned, if none matches.
+func findMapping(
+	mapping config.VersionMapping, v semver.Version,
+	key func(m versionPair) semver.Version,
+) (*versionPair, error) {
+	var found *versionPair
+	for _, up := range slices.Sorted(maps.Keys(mapping.Explicit)) {
+		upv, dv, err := parseMapping(up, mapping.Explicit[up])
+		if err != nil {
+			return nil, err
+		}
+		m := versionPair{up: upv, down: dv}
+		if !semver.SameRelease(key(m), v) {
+			continue
+		}
+		if found == nil || specificity(key(m)) > specificity(key(*found)) {
+			found = &m
+		}
+	}
+	return found, nil
+}
+
+// specificity counts the optional parts the version specifies.
+func specificity(v semver.Version) int {
+	n := 0
+	if v.HasPatch {
+		n++
+	}
+	if v.Pre != "" {
+		n++
+	}
+	return n
+}
+
+func parseMapping(up, down string) (semver.Version, semver.Version, error) {
+	upv, err := semver.Parse(up)
+	if err != nil {
+		return upv, upv, fmt.Errorf("%w: version mapping: %w", ErrSyncFailed, err)
+	}
+	dv, err := semver.Parse(down)
+	if err != nil {
+		return upv, dv, fmt.Errorf("%w: version mapping: %w", ErrSyncFailed, err)
+	}
+	return upv, dv, nil
+}
+
+// withPatch carries over the patch and pre-release of the version onto the
+// mapped one, unless the mapping specifies them.
+func withPatch(mapped, v semver.Version) semver.Version {
+	if !mapped.HasPatch {
+		mapped.Patch, mapped.HasPatch = v.Patch, v.HasPatch
+	}
+	if mapped.Pre == "" {
+		mapped.Pre = v.Pre
+	}
+	return mapped
+}
@@ -0,0 +1,110 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/openshift-knative/deviate/pkg/config"
+	"github.com/openshift-knative/deviate/pkg/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionMapping(t *testing.T) {
+	tcs := []struct {
+		name       string
+		mapping    config.VersionMapping
+		upstream   string
+		downstream string
+	}{{
+		name:       "no mapping",
+		upstream:   "1.14",
+		downstream: "1.14",
+	}, {
+		name:       "offset",
+		mapping:    config.VersionMapping{Offset: config.VersionOffset{Minor: 19}},
+		upstream:   "1.14",
+		downstream: "1.33",
+	}, {
+		name:       "offset of major",
+		mapping:    config.VersionMapping{Offset: config.VersionOffset{Major: 1, Minor: -10}},
+		upstream:   "1.14.2",
+		downstream: "2.4.2",
+	}, {
+		name:       "explicit",
+		mapping:    config.VersionMapping{Explicit: map[string]string{"1.14": "1.33"}},
+		upstream:   "1.14",
+		downstream: "1.33",
+	}, {
+		name:       "explicit carries the patch",
+		mapping:    config.VersionMapping{Explicit: map[string]string{"1.14": "1.33"}},
+		upstream:   "1.14.2",
+		downstream: "1.33.2",
+	}, {
+		name:       "explicit carries the pre-release",
+		mapping:    config.VersionMapping{Explicit: map[string]string{"1.14": "1.33"}},
+		upstream:   "1.14-rc1",
+		downstream: "1.33-rc1",
+	}, {
+		name: "explicit over offset",
+		mapping: config.VersionMapping{
+			Offset:   config.VersionOffset{Minor: 19},
+			Explicit: map[string]string{"1.14": "1.40"},
+		},
+		upstream:   "1.14",
+		downstream: "1.40",
+	}, {
+		name: "offset besides explicit",
+		mapping: config.VersionMapping{
+			Offset:   config.VersionOffset{Minor: 19},
+			Explicit: map[string]string{"1.14": "1.40"},
+		},
+		upstream:   "1.15",
+		downstream: "1.34",
+	}, {
+		name: "most specific patch",
+		mapping: config.VersionMapping{Explicit: map[string]string{
+			"1.14": "1.33", "1.14.2": "1.33.5", "v1.14": "1.33",
+		}},
+		upstream:   "1.14.2",
+		downstream: "1.33.5",
+	}, {
+		name: "less specific patch",
+		mapping: config.VersionMapping{Explicit: map[string]string{
+			"1.14": "1.33", "1.14.2": "1.33.5",
+		}},
+		upstream:   "1.14.1",
+		downstream: "1.33.1",
+	}, {
+		name: "most specific pre-release",
+		mapping: config.VersionMapping{Explicit: map[string]string{
+			"1.14": "1.33", "1.14-rc1": "1.33-beta",
+		}},
+		upstream:   "1.14-rc1",
+		downstream: "1.33-beta",
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			up := parseRelease(t, tc.upstream)
+			down := parseRelease(t, tc.downstream)
+			// The map iteration order differs between the runs.
+			for range 10 {
+				got, err := toDownstream(tc.mapping, up)
+				require.NoError(t, err)
+				assert.Equal(t, tc.downstream, got.String())
+
+				back, err := toUpstream(tc.mapping, down)
+				require.NoError(t, err)
+				assert.Equal(t, tc.upstream, back.String())
+			}
+		})
+	}
+}
+
+func TestVersionMapping_Invalid(t *testing.T) {
+	mapping := config.VersionMapping{Explicit: map[string]string{"1.14": "next"}}
+
+	_, err := toDownstream(mapping, parseRelease(t, "1.14"))
+
+	require.ErrorIs(t, err, ErrSyncFailed)
+	require.ErrorIs(t, err, semver.ErrInvalidVersion)
+}